@@ -0,0 +1,237 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// MaxCompositeActionNesting is how deep GitHub allows composite actions to
+// use other composite actions, counting the outermost action.
+const MaxCompositeActionNesting = 10
+
+// actionCall is a step of a composite action using a local action.
+type actionCall struct {
+	uses *String
+	// to is the directory of the used action.
+	to string
+}
+
+// actionNode is a local action in the graph of composite actions using each
+// other.
+type actionNode struct {
+	dir  string
+	root string
+	// path is the metadata file of the action when it was linted, "" when it
+	// was only read because another action uses it.
+	path  string
+	calls []actionCall
+}
+
+// actionGraph is the graph of local composite actions using each other
+// through `uses: ./...`.
+type actionGraph struct {
+	nodes   map[string]*actionNode
+	actions *localActionCache
+}
+
+func newActionGraph(actions *localActionCache) *actionGraph {
+	return &actionGraph{nodes: map[string]*actionNode{}, actions: actions}
+}
+
+// add adds the action linted from path to the graph.
+func (g *actionGraph) add(path string, meta *ActionMetadata) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	n := g.newNode(filepath.Dir(abs), workspaceRoot(path), meta)
+	n.path = path
+}
+
+func (g *actionGraph) newNode(dir, root string, meta *ActionMetadata) *actionNode {
+	n := &actionNode{dir: dir, root: root}
+	g.nodes[dir] = n
+	if meta == nil || meta.Runs == nil {
+		return n
+	}
+	for _, s := range meta.Runs.Steps {
+		e, ok := s.Exec.(*al.ExecAction)
+		if !ok || e.Uses == nil || !strings.HasPrefix(e.Uses.Value, "./") || e.Uses.ContainsExpression() {
+			continue
+		}
+		n.calls = append(n.calls, actionCall{e.Uses, filepath.Join(root, filepath.FromSlash(e.Uses.Value))})
+	}
+	return n
+}
+
+// node returns the action in dir, reading it from disk if it was not linted.
+func (g *actionGraph) node(dir, root string) *actionNode {
+	if n, ok := g.nodes[dir]; ok {
+		return n
+	}
+	meta, _, _ := g.actions.find(dir)
+	return g.newNode(dir, root, meta)
+}
+
+func (g *actionGraph) name(n *actionNode) string {
+	rel, err := filepath.Rel(n.root, n.dir)
+	if err != nil || rel == "." {
+		return "./"
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// check reports cycles and nesting deeper than GitHub allows among the linted
+// actions. Errors are attached to the `uses:` of the steps of linted actions.
+func (g *actionGraph) check() []*Error {
+	errs := []*Error{}
+
+	linted := []*actionNode{}
+	called := map[string]bool{}
+	for _, n := range g.nodes {
+		if n.path != "" {
+			linted = append(linted, n)
+		}
+	}
+	slices.SortFunc(linted, func(a, b *actionNode) int { return strings.Compare(a.dir, b.dir) })
+	for _, n := range linted {
+		for _, c := range n.calls {
+			called[c.to] = true
+		}
+	}
+
+	// Cycles
+	seen := map[string]bool{}
+	reported := map[string]bool{}
+	for _, n := range linted {
+		for _, cycle := range g.cycles(n, seen) {
+			key := cycleKey(cycle)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			if err := g.cycleError(cycle); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// Nesting depth, only for actions which are not used by other linted
+	// actions to report each chain once
+	depths := map[string][]actionCall{}
+	for _, n := range linted {
+		if called[n.dir] {
+			continue
+		}
+		chain := g.deepest(n, depths, map[string]bool{})
+		if len(chain)+1 <= MaxCompositeActionNesting {
+			continue
+		}
+		names := []string{g.name(n)}
+		for _, c := range chain {
+			names = append(names, c.uses.Value)
+		}
+		errs = append(errs, newError(
+			fmt.Sprintf("composite actions are nested %d levels deep but GitHub allows at most %d levels: %s", len(chain)+1, MaxCompositeActionNesting, strings.Join(names, " -> ")),
+			n.path, chain[0].uses.Pos.Line, chain[0].uses.Pos.Col, "nesting",
+		))
+	}
+
+	return errs
+}
+
+// cycles returns the cycles reachable from n as the calls making them up.
+func (g *actionGraph) cycles(n *actionNode, seen map[string]bool) [][]actionCall {
+	cycles := [][]actionCall{}
+	stack := []actionCall{}
+	onStack := map[string]int{n.dir: 0}
+
+	var visit func(n *actionNode)
+	visit = func(n *actionNode) {
+		if seen[n.dir] {
+			return
+		}
+		for _, c := range n.calls {
+			if i, ok := onStack[c.to]; ok {
+				cycle := slices.Clone(stack[i:])
+				cycles = append(cycles, append(cycle, c))
+				continue
+			}
+			onStack[c.to] = len(stack) + 1
+			stack = append(stack, c)
+			visit(g.node(c.to, n.root))
+			stack = stack[:len(stack)-1]
+			delete(onStack, c.to)
+		}
+		seen[n.dir] = true
+	}
+	visit(n)
+	return cycles
+}
+
+// cycleKey identifies a cycle regardless of the action it starts from.
+func cycleKey(cycle []actionCall) string {
+	dirs := make([]string, 0, len(cycle))
+	for _, c := range cycle {
+		dirs = append(dirs, c.to)
+	}
+	slices.Sort(dirs)
+	return strings.Join(dirs, "\x00")
+}
+
+// cycleError reports cycle at the call in the first linted action of the
+// cycle.
+func (g *actionGraph) cycleError(cycle []actionCall) *Error {
+	// cycle[i] is made in the action cycle[i-1] calls, and the last call
+	// goes back to the action making the first one.
+	from := func(i int) *actionNode {
+		return g.nodes[cycle[(i+len(cycle)-1)%len(cycle)].to]
+	}
+
+	start := -1
+	for i := range cycle {
+		if n := from(i); n != nil && n.path != "" && (start < 0 || n.dir < from(start).dir) {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	n := from(start)
+	names := []string{g.name(n)}
+	for i := range cycle {
+		names = append(names, cycle[(start+i)%len(cycle)].uses.Value)
+	}
+	c := cycle[start]
+	return newError(
+		fmt.Sprintf("local composite actions use each other in a cycle: %s", strings.Join(names, " -> ")),
+		n.path, c.uses.Pos.Line, c.uses.Pos.Col, "nesting",
+	)
+}
+
+// deepest returns the longest chain of calls starting at n, ignoring calls
+// which close a cycle.
+func (g *actionGraph) deepest(n *actionNode, memo map[string][]actionCall, visiting map[string]bool) []actionCall {
+	if chain, ok := memo[n.dir]; ok {
+		return chain
+	}
+	visiting[n.dir] = true
+	var longest []actionCall
+	for _, c := range n.calls {
+		if visiting[c.to] {
+			continue
+		}
+		chain := g.deepest(g.node(c.to, n.root), memo, visiting)
+		if len(chain)+1 > len(longest) {
+			longest = append([]actionCall{c}, chain...)
+		}
+	}
+	delete(visiting, n.dir)
+	memo[n.dir] = longest
+	return longest
+}
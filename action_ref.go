@@ -0,0 +1,139 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type actionRefKind int
+
+const (
+	// {owner}/{repo}[/{path}]@{ref}
+	actionRefRepo actionRefKind = iota
+	// ./{path}
+	actionRefLocal
+	// docker://{image}[:{tag}|@{digest}]
+	actionRefDocker
+)
+
+// actionRef is a parsed value of `uses:` in a step.
+type actionRef struct {
+	kind  actionRefKind
+	owner string
+	repo  string
+	// path is the directory of the action within the repository for repo
+	// actions and the whole path for local actions.
+	path string
+	ref  string
+	// image, tag and digest are set for docker actions.
+	image  string
+	tag    string
+	digest string
+}
+
+var (
+	dockerImageRe  = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+(?::[0-9]+)?/)?[a-z0-9]+(?:[._-]+[a-z0-9]+)*(?:/[a-z0-9]+(?:[._-]+[a-z0-9]+)*)*$`)
+	dockerTagRe    = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+	dockerDigestRe = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+// parseActionRef parses spec, the value of `uses:` in a step. When it is
+// invalid the error explains why.
+func parseActionRef(spec string) (*actionRef, error) {
+	if strings.ContainsAny(spec, " \t\n") {
+		return nil, fmt.Errorf("it contains whitespace")
+	}
+
+	if strings.HasPrefix(spec, "./") {
+		return parseLocalActionRef(spec)
+	}
+	if strings.HasPrefix(spec, "docker://") {
+		return parseDockerActionRef(spec)
+	}
+	if strings.HasPrefix(spec, "docker:") {
+		return nil, fmt.Errorf("docker images must be referenced as \"docker://{image}\"")
+	}
+	return parseRepoActionRef(spec)
+}
+
+func parseLocalActionRef(spec string) (*actionRef, error) {
+	if strings.Contains(spec, "@") {
+		return nil, fmt.Errorf("local actions are used from the checked out workspace and cannot have a ref")
+	}
+	path := strings.TrimSuffix(spec[len("./"):], "/")
+	if path != "" && slices.Contains(strings.Split(path, "/"), "") {
+		return nil, fmt.Errorf("its path contains an empty segment")
+	}
+	return &actionRef{kind: actionRefLocal, path: spec}, nil
+}
+
+func parseDockerActionRef(spec string) (*actionRef, error) {
+	r := &actionRef{kind: actionRefDocker}
+	s := spec[len("docker://"):]
+
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		s, r.digest = s[:i], s[i+1:]
+		if !dockerDigestRe.MatchString(r.digest) {
+			return nil, fmt.Errorf("digest %q is malformed. it should look like \"sha256:{hex}\"", r.digest)
+		}
+	}
+	if i := strings.LastIndexByte(s, ':'); i >= 0 && !strings.Contains(s[i:], "/") {
+		s, r.tag = s[:i], s[i+1:]
+		if r.tag == "" {
+			return nil, fmt.Errorf("the tag after \":\" is empty")
+		}
+		if !dockerTagRe.MatchString(r.tag) {
+			return nil, fmt.Errorf("tag %q of the docker image is malformed", r.tag)
+		}
+	}
+
+	r.image = s
+	if s == "" {
+		return nil, fmt.Errorf("the docker image is missing")
+	}
+	if slices.Contains(strings.Split(s, "/"), "") {
+		return nil, fmt.Errorf("the docker image %q contains an empty path segment", s)
+	}
+	if !dockerImageRe.MatchString(s) {
+		return nil, fmt.Errorf("the docker image %q is malformed. image names consist of lower case letters, digits and separators", s)
+	}
+	return r, nil
+}
+
+func parseRepoActionRef(spec string) (*actionRef, error) {
+	s, ref, hasRef := strings.Cut(spec, "@")
+	segs := strings.Split(s, "/")
+
+	if s == "" {
+		return nil, fmt.Errorf("the repository is missing before \"@\"")
+	}
+	if len(segs) < 2 {
+		why := fmt.Sprintf("the owner is missing. it should look like \"{owner}/%s\"", spec)
+		if !hasRef {
+			why += ". use \"./\" as prefix to use a local action"
+		}
+		return nil, fmt.Errorf("%s", why)
+	}
+	if slices.Contains(segs, "") {
+		return nil, fmt.Errorf("its path contains an empty segment")
+	}
+	if !hasRef {
+		return nil, fmt.Errorf("the ref is missing. it should look like \"%s@{ref}\"", spec)
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("the ref after \"@\" is empty")
+	}
+	if strings.Contains(ref, "@") {
+		return nil, fmt.Errorf("it contains more than one \"@\"")
+	}
+
+	return &actionRef{
+		kind:  actionRefRepo,
+		owner: segs[0],
+		repo:  segs[1],
+		path:  strings.Join(segs[2:], "/"),
+		ref:   ref,
+	}, nil
+}
@@ -24,7 +24,9 @@ type Step struct {
 	Exec actionlint.Exec
 	// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runsstepscontinue-on-error
 	ContinueOnError *Bool
-	// Pos is the position of the step in the yaml source
+	// Pos is the position of the step in the yaml source. For a step written
+	// as an alias, it is the position of the alias and so is the position of
+	// its ID, while the other values keep the positions of the anchor.
 	Pos *Pos
 }
 
@@ -34,6 +36,14 @@ type Runs struct {
 	Using *String
 	// Steps is a list of steps that make up composite action, if this is one
 	Steps []*Step
+	// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runsmain
+	Main *String
+	// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runspre
+	Pre *String
+	// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runspost
+	Post *String
+	// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runsimage
+	Image *String
 }
 
 // https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#inputs
@@ -50,6 +50,12 @@ func MetadataKeyAvailability(key string) ([]string, []string) {
 
 var ALSpecialFunctionNames = actionlint.SpecialFunctionNames
 
+// Restore the special function availability of workflows before handing
+// workflow files to actionlint.
+func RestoreSpecialFunctionNames() {
+	actionlint.SpecialFunctionNames = ALSpecialFunctionNames
+}
+
 // Ensure the semantic checks in actionlint give the right error messaegs
 func UpdateSpecialFunctionNames() {
 
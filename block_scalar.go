@@ -0,0 +1,185 @@
+package compositeactionlint
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// blockScalarSegment records where a run of bytes in the value of a block
+// scalar starts in the source.
+type blockScalarSegment struct {
+	offset int
+	pos    Pos
+}
+
+// blockScalar maps byte offsets in the value of a literal (|) or folded (>)
+// block scalar back to lines and columns in the source. The value of such a
+// string starts on the line after its header and has the indentation of each
+// line removed, so the position of the String alone is not enough.
+type blockScalar struct {
+	value string
+	segs  []blockScalarSegment
+}
+
+// newBlockScalar returns nil when s is not a block scalar in src.
+func newBlockScalar(src []string, s *String) *blockScalar {
+	if s == nil || s.Pos == nil || s.Quoted || s.Pos.Line < 1 || s.Pos.Line > len(src) {
+		return nil
+	}
+
+	header := src[s.Pos.Line-1]
+	start := byteOffsetOfCol(header, s.Pos.Col)
+	if start >= len(header) || (header[start] != '|' && header[start] != '>') {
+		return nil
+	}
+
+	// The header may carry an indentation indicator and a chomping indicator
+	// in either order, like "|2-" or ">+1". Chomping only affects trailing
+	// line breaks so it does not matter for positions.
+	indent := -1
+	for _, c := range header[start+1:] {
+		if c >= '1' && c <= '9' {
+			indent = parentIndent(header) + int(c-'0')
+		} else if c != '+' && c != '-' {
+			break
+		}
+	}
+
+	b := &blockScalar{value: s.Value}
+	v := 0
+	for i := s.Pos.Line; i < len(src) && v < len(s.Value); i++ {
+		l := src[i]
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		lineIndent := len(l) - len(strings.TrimLeft(l, " "))
+		if indent < 0 {
+			// Auto-detected from the first non-empty line
+			indent = lineIndent
+		}
+		if lineIndent < indent {
+			break // The block scalar ended
+		}
+		content := l[indent:]
+
+		idx := strings.Index(s.Value[v:], content)
+		if idx < 0 {
+			break
+		}
+		b.segs = append(b.segs, blockScalarSegment{
+			offset: v + idx,
+			pos:    Pos{Line: i + 1, Col: indent + 1},
+		})
+		v += idx + len(content)
+	}
+
+	if len(b.segs) == 0 {
+		return nil
+	}
+	return b
+}
+
+// newPlainScalar maps a plain scalar continued on the following lines, which
+// are folded into the value with their indentation removed, like a folded
+// block scalar. It returns nil when s is not such a scalar in src.
+func newPlainScalar(src []string, s *String) *blockScalar {
+	if s == nil || s.Pos == nil || s.Quoted || s.Pos.Line < 1 || s.Pos.Line > len(src) {
+		return nil
+	}
+
+	header := src[s.Pos.Line-1]
+	first := strings.TrimRight(header[byteOffsetOfCol(header, s.Pos.Col):], " \t")
+	if len(first) >= len(s.Value) || !strings.HasPrefix(s.Value, first) {
+		return nil // The value fits on its first line
+	}
+
+	b := &blockScalar{value: s.Value, segs: []blockScalarSegment{{offset: 0, pos: *s.Pos}}}
+	v := len(first)
+	for i := s.Pos.Line; i < len(src) && v < len(s.Value); i++ {
+		content := strings.TrimSpace(src[i])
+		if content == "" {
+			continue // Folded into a line break
+		}
+		// Lines are joined with a space, or the line breaks of empty lines
+		for v < len(s.Value) && (s.Value[v] == ' ' || s.Value[v] == '\n') {
+			v++
+		}
+		if !strings.HasPrefix(s.Value[v:], content) {
+			return nil
+		}
+		indent := len(src[i]) - len(strings.TrimLeft(src[i], " \t"))
+		b.segs = append(b.segs, blockScalarSegment{
+			offset: v,
+			pos:    Pos{Line: i + 1, Col: indent + 1},
+		})
+		v += len(content)
+	}
+	return b
+}
+
+// posAt returns the source position of the byte at offset in the value.
+// Columns are counted in characters like the ones of the YAML parser.
+func (b *blockScalar) posAt(offset int) Pos {
+	seg := b.segs[0]
+	for _, s := range b.segs[1:] {
+		if s.offset > offset {
+			break
+		}
+		seg = s
+	}
+	return Pos{Line: seg.pos.Line, Col: seg.pos.Col + utf8.RuneCountInString(b.value[seg.offset:offset])}
+}
+
+// parentIndent is the indentation of the node which owns the block scalar on
+// the header line, taking block sequence entries like "- run: |" into
+// account.
+func parentIndent(header string) int {
+	i := 0
+	for {
+		for i < len(header) && header[i] == ' ' {
+			i++
+		}
+		if !strings.HasPrefix(header[i:], "- ") {
+			return i
+		}
+		i += 2
+	}
+}
+
+// byteOffsetOfCol converts a 1-based column counted in characters, as
+// reported by the YAML parser, to a byte offset in line.
+func byteOffsetOfCol(line string, col int) int {
+	off := 0
+	for n := 1; n < col && off < len(line); n++ {
+		_, size := utf8.DecodeRuneInString(line[off:])
+		off += size
+	}
+	return off
+}
+
+// sourceLines splits src into lines, for looking up positions of strings.
+func sourceLines(src []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+}
+
+// stringPos returns a function converting byte offsets in the value of s to
+// positions in src.
+func stringPos(src []string, s *String) func(offset int) Pos {
+	// Strings like `run: |` start on the line after their header and have
+	// their indentation removed, so positions are looked up in the source.
+	if bs := newBlockScalar(src, s); bs != nil {
+		return bs.posAt
+	}
+	if ps := newPlainScalar(src, s); ps != nil {
+		return ps.posAt
+	}
+	// TODO: Positions are not correct when a quoted string spans several
+	// lines, since its line breaks are folded and escapes are resolved.
+	line, col := s.Pos.Line, s.Pos.Col
+	if s.Quoted {
+		col++ // when the string is quoted like 'foo' or "foo", column should be incremented
+	}
+	return func(offset int) Pos {
+		return Pos{Line: line, Col: col + utf8.RuneCountInString(s.Value[:offset])}
+	}
+}
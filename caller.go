@@ -0,0 +1,208 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// callerChecker validates how workflows call local composite actions
+// (`uses: ./path/to/action`) against the metadata of those actions.
+type callerChecker struct {
+	RuleBase
+	src []string
+	// root is the directory local action paths are relative to, which is
+	// the root of the repository of the workflow.
+	root string
+	// actions caches the metadata of the called actions.
+	actions *localActionCache
+}
+
+func newCallerChecker(actions *localActionCache) *callerChecker {
+	return &callerChecker{
+		RuleBase: RuleBase{
+			name: "caller",
+			desc: "Checks calls of local composite actions from workflows against their metadata",
+		},
+		actions: actions,
+	}
+}
+
+// check validates calls of local actions in the workflow at path.
+func (c *callerChecker) check(path string, content []byte) []*Error {
+	c.errs = nil
+	c.src = sourceLines(content)
+	c.root = workspaceRoot(path)
+
+	// Syntax errors are reported by actionlint
+	w, _ := al.Parse(content)
+	if w == nil {
+		return nil
+	}
+
+	for _, job := range w.Jobs {
+		c.checkJob(job)
+	}
+
+	for _, err := range c.errs {
+		err.Filepath = path
+	}
+	return c.errs
+}
+
+func (c *callerChecker) checkJob(job *al.Job) {
+	// Metadata of local actions by the ID of the steps calling them
+	called := map[string]*ActionMetadata{}
+
+	for _, step := range job.Steps {
+		for _, s := range stepStrings(step) {
+			c.checkOutputRefs(s, called)
+		}
+
+		e, ok := step.Exec.(*al.ExecAction)
+		if !ok || e.Uses == nil || !strings.HasPrefix(e.Uses.Value, "./") {
+			continue
+		}
+		meta := c.findAction(e.Uses)
+		if meta == nil {
+			continue
+		}
+		c.checkInputs(e, meta)
+		if step.ID != nil && !step.ID.ContainsExpression() {
+			called[strings.ToLower(step.ID.Value)] = meta
+		}
+	}
+
+	for _, o := range job.Outputs {
+		c.checkOutputRefs(o.Value, called)
+	}
+}
+
+// findAction returns the parsed metadata of the local action used by uses, or
+// nil when it cannot be found or parsed.
+func (c *callerChecker) findAction(uses *String) *ActionMetadata {
+	meta, found, err := c.actions.find(filepath.Join(c.root, filepath.FromSlash(uses.Value)))
+	if err != nil {
+		c.Errorf(uses.Pos, "could not read metadata of local action %q: %s", uses.Value, err)
+	} else if !found {
+		c.Errorf(uses.Pos, "neither action.yml nor action.yaml found for local action %q", uses.Value)
+	}
+	return meta
+}
+
+func (c *callerChecker) checkInputs(e *al.ExecAction, meta *ActionMetadata) {
+	names := make([]string, 0, len(meta.Inputs))
+	for id := range meta.Inputs {
+		names = append(names, id)
+	}
+	slices.Sort(names)
+
+	for id, i := range e.Inputs {
+		if _, ok := meta.Inputs[id]; ok {
+			continue
+		}
+		m := fmt.Sprintf("input %q is not defined in local action %q. available inputs are [%s]", i.Name.Value, e.Uses.Value, strings.Join(names, ","))
+		if len(names) == 0 {
+			m = fmt.Sprintf("input %q is not defined in local action %q since it takes no inputs", i.Name.Value, e.Uses.Value)
+		}
+		if s := closestMatch(id, names); s != "" {
+			m += fmt.Sprintf(". did you mean %q?", s)
+		}
+		c.Error(i.Name.Pos, m)
+	}
+
+	for _, id := range names {
+		i := meta.Inputs[id]
+		if i.Required == nil || !i.Required.Value || i.Default != nil {
+			continue
+		}
+		if _, ok := e.Inputs[id]; !ok {
+			c.Errorf(e.Uses.Pos, "missing input %q which is required by local action %q", i.ID.Value, e.Uses.Value)
+		}
+	}
+}
+
+// checkOutputRefs reports `steps.<id>.outputs.<name>` in s where step <id>
+// calls a local action which does not define output <name>.
+func (c *callerChecker) checkOutputRefs(s *String, called map[string]*ActionMetadata) {
+	if s == nil || len(called) == 0 {
+		return
+	}
+
+	posAt := stringPos(c.src, s)
+
+	visitExprsIn(s.Value, func(expr ExprNode, offset int) {
+		al.VisitExprNode(expr, func(n, _ al.ExprNode, entering bool) {
+			if !entering {
+				return
+			}
+			id, output, ok := stepOutputRef(n)
+			if !ok {
+				return
+			}
+			meta, ok := called[strings.ToLower(id)]
+			if !ok {
+				return
+			}
+			if _, ok := meta.Outputs[strings.ToLower(output)]; ok {
+				return
+			}
+
+			names := make([]string, 0, len(meta.Outputs))
+			for o := range meta.Outputs {
+				names = append(names, o)
+			}
+			slices.Sort(names)
+			m := fmt.Sprintf("output %q is not defined in the local action called by step %q. available outputs are [%s]", output, id, strings.Join(names, ","))
+			if s := closestMatch(strings.ToLower(output), names); s != "" {
+				m += fmt.Sprintf(". did you mean %q?", s)
+			}
+			pos := posAt(offset + n.Token().Offset)
+			c.Error(&pos, m)
+		})
+	})
+}
+
+// stepOutputRef matches n against `steps.<id>.outputs.<name>`.
+func stepOutputRef(n al.ExprNode) (string, string, bool) {
+	out, ok := n.(*al.ObjectDerefNode)
+	if !ok {
+		return "", "", false
+	}
+	outputs, ok := out.Receiver.(*al.ObjectDerefNode)
+	if !ok || !strings.EqualFold(outputs.Property, "outputs") {
+		return "", "", false
+	}
+	step, ok := outputs.Receiver.(*al.ObjectDerefNode)
+	if !ok {
+		return "", "", false
+	}
+	steps, ok := step.Receiver.(*al.VariableNode)
+	if !ok || !strings.EqualFold(steps.Name, "steps") {
+		return "", "", false
+	}
+	return step.Property, out.Property, true
+}
+
+// stepStrings lists the values of a workflow step which may contain
+// expressions.
+func stepStrings(step *al.Step) []*String {
+	ss := []*String{step.Name, conditionString(step.If)}
+	if step.Env != nil {
+		for _, e := range step.Env.Vars {
+			ss = append(ss, e.Value)
+		}
+	}
+	switch e := step.Exec.(type) {
+	case *al.ExecRun:
+		ss = append(ss, e.Run, e.WorkingDirectory)
+	case *al.ExecAction:
+		for _, i := range e.Inputs {
+			ss = append(ss, i.Value)
+		}
+	}
+	return ss
+}
@@ -22,7 +22,28 @@ To check any actions, pass the path to their metadata files as arguments.
 
   $ composite-action-lint path/to-action/action.yml another/action.yaml
 
-It takes no options or configuration at present.
+Workflow files may be passed as well. They are recognized by their content
+and checked with actionlint in the same run.
+
+  $ composite-action-lint .github/actions/*/action.yml .github/workflows/*.yml
+
+With -check-callers, steps in workflow files which use local composite actions
+(uses: ./path/to/action) are checked against the metadata of those actions:
+inputs passed with "with:" must be defined, required inputs must be passed and
+outputs referenced through steps.<id>.outputs must exist.
+
+  $ composite-action-lint -check-callers .github/workflows/*.yml
+
+Rules are configured in .github/composite-action-lint.yaml, or the file given
+with -config. For example, to require actions to be pinned to a full commit
+SHA except for release tags of GitHub's own actions:
+
+  pinning:
+    require-sha: true
+    allow-tags: ["actions/*"]
+    forbid-branches: true
+
+Options:
 `)
 }
 
@@ -34,6 +55,8 @@ type Command struct {
 
 func (cmd *Command) Main(args []string) int {
 	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	configPath := flags.String("config", "", "Path to the configuration file. By default .github/composite-action-lint.yaml is used when it exists")
+	checkCallers := flags.Bool("check-callers", false, "Check calls of local composite actions in workflow files against the metadata of the actions")
 	flags.SetOutput(cmd.Stderr)
 	flags.Usage = func() {
 		printUsageHeader(cmd.Stderr)
@@ -46,7 +69,19 @@ func (cmd *Command) Main(args []string) int {
 		return ExitStatusInvalidInvocation
 	}
 
-	l := &Linter{out: cmd.Stdout}
+	var config *Config
+	var err error
+	if *configPath != "" {
+		config, err = ReadConfigFile(*configPath)
+	} else {
+		config, err = findConfig()
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(cmd.Stderr, err.Error())
+		return ExitStatusInvalidInvocation
+	}
+
+	l := &Linter{out: cmd.Stdout, config: config, checkCallers: *checkCallers}
 	errs, err := l.LintFiles(flags.Args())
 	if err != nil {
 		_, _ = fmt.Fprintln(cmd.Stderr, err.Error())
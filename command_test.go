@@ -2,6 +2,7 @@ package compositeactionlint
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,6 +16,15 @@ func TestCommandMain_Ok(t *testing.T) {
 		"./testdata/ok/single-action-step/action.yml",
 		"./testdata/ok/single-shell-step/action.yml",
 		"./testdata/ok/uses-inputs/action.yml",
+		"./testdata/ok/javascript-action/action.yml",
+		"./testdata/ok/anchors-and-aliases/action.yml",
+		"./testdata/ok/docker-action/action.yml",
+		"./testdata/ok/action-scripts/action.yml",
+		"./testdata/ok/custom-shell/action.yml",
+		"./testdata/ok/conditional-outputs/action.yml",
+		"./testdata/ok/npm-scripts/action.yml",
+		"./testdata/ok/exported-env/action.yml",
+		"./testdata/ok/workflow-file/ci.yml",
 	}
 
 	for _, filepath := range files {
@@ -35,6 +45,34 @@ func TestCommandMain_BadActions(t *testing.T) {
 	files := []string{
 		"./testdata/examples/uses-and-run-step/action.yml",
 		"./testdata/examples/steps-in-js-action/action.yml",
+		"./testdata/examples/unknown-input-key/action.yml",
+		"./testdata/examples/error-in-block-scalar/action.yml",
+		"./testdata/examples/invalid-yaml/action.yml",
+		"./testdata/examples/invalid-yaml-unicode/action.yml",
+		"./testdata/examples/tab-indentation/action.yml",
+		"./testdata/examples/tab-in-steps/action.yml",
+		"./testdata/examples/merge-key/action.yml",
+		"./testdata/examples/aliased-step/action.yml",
+		"./testdata/examples/multiple-documents/action.yml",
+		"./testdata/examples/workflow-step-keys/action.yml",
+		"./testdata/examples/invalid-uses/action.yml",
+		"./testdata/cycle/.github/actions/a/action.yml",
+		"./testdata/deep/.github/actions/level01/action.yml",
+		"./testdata/examples/local-uses-in-published-action/action.yml",
+		"./testdata/examples/relative-script-path/action.yml",
+		"./testdata/examples/missing-files/action.yml",
+		"./testdata/examples/missing-dockerfile/action.yml",
+		"./testdata/examples/unknown-shell/action.yml",
+		"./testdata/examples/step-ids/action.yml",
+		"./testdata/examples/literal-values/action.yml",
+		"./testdata/examples/unused-inputs/action.yml",
+		"./testdata/examples/conditional-outputs/action.yml",
+		"./testdata/examples/later-step-reference/action.yml",
+		"./testdata/examples/env-var-names/action.yml",
+		"./testdata/examples/env-in-own-condition/action.yml",
+		"./testdata/examples/deprecated-commands/action.yml",
+		"./testdata/examples/typo-in-step-reference/action.yml",
+		"./testdata/examples/workflow-file/ci.yml",
 	}
 
 	for _, filepath := range files {
@@ -49,3 +87,204 @@ func TestCommandMain_BadActions(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandMain_WorkflowsAndActions(t *testing.T) {
+	var testOut bytes.Buffer
+	c := Command{Stdout: &testOut, Stderr: &testOut}
+	exitCode := c.Main([]string{
+		argv0,
+		"./testdata/ok/uses-inputs/action.yml",
+		"./testdata/examples/workflow-file/ci.yml",
+		"./testdata/examples/typo-in-input-usage/action.yml",
+	})
+
+	t.Log(testOut.String())
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, testOut.String(), "testdata/examples/workflow-file/ci.yml:10:")
+	assert.Contains(t, testOut.String(), "testdata/examples/typo-in-input-usage/action.yml:11:21:")
+}
+
+func TestCommandMain_Positions(t *testing.T) {
+	cases := []struct {
+		filepath string
+		want     []string
+	}{
+		{
+			"./testdata/examples/error-in-block-scalar/action.yml",
+			[]string{
+				"error-in-block-scalar/action.yml:14:39:",
+				"error-in-block-scalar/action.yml:19:13:",
+				"error-in-block-scalar/action.yml:22:22:",
+				"error-in-block-scalar/action.yml:25:13:",
+				"error-in-block-scalar/action.yml:28:25:",
+			},
+		},
+		{
+			"./testdata/examples/invalid-yaml/action.yml",
+			[]string{"invalid-yaml/action.yml:9:6:"},
+		},
+		{
+			"./testdata/examples/invalid-yaml-unicode/action.yml",
+			[]string{"invalid-yaml-unicode/action.yml:1:19: could not parse as YAML: mapping values are not allowed in this context at \": x: yéééééééééééééé...\""},
+		},
+		{
+			"./testdata/examples/tab-indentation/action.yml",
+			[]string{"tab-indentation/action.yml:5:1: could not parse as YAML: found character that cannot start any token at \"using: composite\". indent with spaces instead of tabs"},
+		},
+		{
+			"./testdata/examples/tab-in-steps/action.yml",
+			[]string{"tab-in-steps/action.yml:9:1:"},
+		},
+		{
+			"./testdata/examples/invalid-uses/action.yml",
+			[]string{"invalid-uses/action.yml:16:13: invalid action reference \"@v5\" since the repository is missing before \"@\""},
+		},
+		{
+			"./testdata/examples/env-var-names/action.yml",
+			[]string{
+				"env-var-names/action.yml:11:9: environment variable \"GITHUB_WORKSPACE\" is reserved.",
+				"env-var-names/action.yml:15:9: environment variable \"CI\" is set by the runner. overriding it is not guaranteed to keep working",
+			},
+		},
+		{
+			"./testdata/examples/typo-in-input-usage/action.yml",
+			[]string{"typo-in-input-usage/action.yml:11:21: property \"desrciption\" is not defined in object type {description: any}. did you mean \"description\"?"},
+		},
+		{
+			"./testdata/examples/typo-in-step-reference/action.yml",
+			[]string{"typo-in-step-reference/action.yml:13:58: property \"tset\" is not defined in object type {build: {conclusion: string; outcome: string; outputs: {string => string}}; test: {conclusion: string; outcome: string; outputs: {string => string}}}. did you mean \"test\"?"},
+		},
+		{
+			"./testdata/examples/missing-files/action.yml",
+			[]string{"missing-files/action.yml:13:18: file \"scripts/release.sh\" referenced by the script does not exist in the directory of this action. did you mean \"scripts/re%slease.sh\"?"},
+		},
+		{
+			"./testdata/examples/literal-values/action.yml",
+			[]string{
+				"literal-values/action.yml:5:3: input \"version\" is defined but never used",
+				"literal-values/action.yml:8:3: input \"shell\" is defined but never used",
+			},
+		},
+		{
+			"./testdata/examples/conditional-outputs/action.yml",
+			[]string{"conditional-outputs/action.yml:15:56: output \"link\" of the action depends only on \"steps.deploy.outputs.url\""},
+		},
+		{
+			"./testdata/examples/step-ids/action.yml",
+			[]string{
+				"step-ids/action.yml:15:11: step ID \"Build\" duplicates. previously defined at line:12,col:11.",
+				"step-ids/action.yml:24:11: step ID \"cleanup\" is not referenced",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.filepath, func(t *testing.T) {
+			var testOut bytes.Buffer
+			c := Command{Stdout: &testOut, Stderr: &testOut}
+			exitCode := c.Main([]string{argv0, tc.filepath})
+
+			t.Log(testOut.String())
+			assert.Equal(t, 1, exitCode)
+			for _, w := range tc.want {
+				assert.Contains(t, testOut.String(), w)
+			}
+		})
+	}
+}
+
+func TestCommandMain_ErrorsSortedByPosition(t *testing.T) {
+	var testOut bytes.Buffer
+	c := Command{Stdout: &testOut, Stderr: &testOut}
+	exitCode := c.Main([]string{argv0, "./testdata/examples/typo-in-input-usage/action.yml"})
+
+	t.Log(testOut.String())
+	assert.Equal(t, 1, exitCode)
+	unused := strings.Index(testOut.String(), "typo-in-input-usage/action.yml:5:3:")
+	typo := strings.Index(testOut.String(), "typo-in-input-usage/action.yml:11:21:")
+	assert.NotEqual(t, -1, unused)
+	assert.NotEqual(t, -1, typo)
+	assert.Less(t, unused, typo)
+}
+
+func TestCommandMain_ReservedEnvVarsMatchCase(t *testing.T) {
+	var testOut bytes.Buffer
+	c := Command{Stdout: &testOut, Stderr: &testOut}
+	exitCode := c.Main([]string{argv0, "./testdata/examples/env-var-names/action.yml"})
+
+	t.Log(testOut.String())
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, testOut.String(), "environment variable \"GITHUB_WORKSPACE\" is reserved")
+	assert.NotContains(t, testOut.String(), "\"github_sha\"")
+}
+
+func TestCommandMain_NoSuggestionForShortNames(t *testing.T) {
+	var testOut bytes.Buffer
+	c := Command{Stdout: &testOut, Stderr: &testOut}
+	exitCode := c.Main([]string{argv0, "./testdata/examples/short-property-name/action.yml"})
+
+	t.Log(testOut.String())
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, testOut.String(), "property \"x1\" is not defined")
+	assert.NotContains(t, testOut.String(), "did you mean")
+}
+
+func TestCommandMain_CheckCallers(t *testing.T) {
+	cases := []struct {
+		filepath string
+		want     int
+		msgs     []string
+	}{
+		{"./testdata/callers/.github/workflows/ok.yml", 0, nil},
+		{
+			"./testdata/callers/.github/workflows/bad.yml",
+			1,
+			[]string{
+				"bad.yml:14:11: input \"greting\" is not defined in local action \"./.github/actions/greet\". available inputs are [greeting,who]. did you mean \"greeting\"?",
+				"bad.yml:17:21: output \"mesage\" is not defined in the local action called by step \"greet\". available outputs are [message]. did you mean \"message\"?",
+				"bad.yml:21:11: input \"name\" is not defined in local action \"./.github/actions/format\". available inputs are [name%s]. did you mean \"name%s\"?",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.filepath, func(t *testing.T) {
+			var testOut bytes.Buffer
+			c := Command{Stdout: &testOut, Stderr: &testOut}
+			exitCode := c.Main([]string{argv0, "-check-callers", tc.filepath})
+
+			t.Log(testOut.String())
+			assert.Equal(t, tc.want, exitCode)
+			for _, m := range tc.msgs {
+				assert.Contains(t, testOut.String(), m)
+			}
+		})
+	}
+}
+
+func TestCommandMain_Config(t *testing.T) {
+	cases := []struct {
+		config   string
+		filepath string
+		want     int
+	}{
+		{"./testdata/config/pinning.yaml", "./testdata/pinned/.github/actions/pinned-actions/action.yml", 0},
+		{"./testdata/config/pinning.yaml", "./testdata/examples/unpinned-actions/action.yml", 1},
+		{"./testdata/config/policy.yaml", "./testdata/pinned/.github/actions/pinned-actions/action.yml", 0},
+		{"./testdata/config/policy.yaml", "./testdata/examples/denied-actions/action.yml", 1},
+		{"./testdata/config/deny-org.yaml", "./testdata/examples/denied-org-case/action.yml", 1},
+		{"./testdata/config/runner-os.yaml", "./testdata/ok/custom-shell/action.yml", 0},
+		{"./testdata/config/runner-os.yaml", "./testdata/examples/windows-shell/action.yml", 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.config+":"+tc.filepath, func(t *testing.T) {
+			var testOut bytes.Buffer
+			c := Command{Stdout: &testOut, Stderr: &testOut}
+			exitCode := c.Main([]string{argv0, "-config", tc.config, tc.filepath})
+
+			t.Log(testOut.String())
+			assert.Equal(t, tc.want, exitCode)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package compositeactionlint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of composite-action-lint, read from
+// .github/composite-action-lint.yaml or the file passed with -config.
+type Config struct {
+	// Pinning is the policy for refs of actions used by composite steps. The
+	// pinning rule is disabled when it is nil.
+	Pinning *PinningConfig `yaml:"pinning"`
+	// Policy restricts which actions may be used by composite steps. The
+	// policy rule is disabled when it is nil.
+	Policy *PolicyConfig `yaml:"policy"`
+	// RunnerOS lists the operating systems of the runners the actions run
+	// on, out of "linux", "macos" and "windows". Shells of run steps are
+	// checked to be available on all of them.
+	RunnerOS []string `yaml:"runner-os"`
+}
+
+// PinningConfig is the policy for the refs actions are used at.
+type PinningConfig struct {
+	// RequireSHA requires actions to be pinned to a full commit SHA and
+	// docker images to be pinned by digest.
+	RequireSHA bool `yaml:"require-sha"`
+	// AllowTags are patterns of "{owner}/{repo}" which may be used at a
+	// release tag even when RequireSHA is set, like "actions/*".
+	AllowTags []string `yaml:"allow-tags"`
+	// ForbidBranches forbids refs which look like branches, like "main".
+	ForbidBranches bool `yaml:"forbid-branches"`
+}
+
+// PolicyConfig lists the actions composite steps may or must not use.
+type PolicyConfig struct {
+	// Allow lists the actions which may be used. Any action may be used
+	// when it is empty, unless denied.
+	Allow []*PolicyEntry `yaml:"allow"`
+	// Deny lists the actions which must not be used. It takes precedence
+	// over Allow.
+	Deny []*PolicyEntry `yaml:"deny"`
+}
+
+// PolicyEntry is a pattern of actions in the policy, like
+// "{owner}/{repo}[/{path}][@{ref}]" or "docker://{image}". Each part may
+// contain glob wildcards. Entries are written either as the pattern alone or
+// as a mapping with the pattern and the reason for the entry.
+type PolicyEntry struct {
+	Pattern string `yaml:"pattern"`
+	Reason  string `yaml:"reason"`
+}
+
+func (e *PolicyEntry) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		e.Pattern = n.Value
+		return nil
+	}
+	type entry PolicyEntry
+	return n.Decode((*entry)(e))
+}
+
+// defaultConfigFiles are looked up in the current directory when no
+// configuration file is given.
+var defaultConfigFiles = []string{
+	filepath.Join(".github", "composite-action-lint.yaml"),
+	filepath.Join(".github", "composite-action-lint.yml"),
+}
+
+func parseConfig(b []byte, path string) (*Config, error) {
+	var c Config
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&c); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse config file %q: %w", path, err)
+	}
+	if c.Pinning != nil {
+		for _, pat := range c.Pinning.AllowTags {
+			if _, err := pathpkg.Match(pat, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in pinning.allow-tags of config file %q: %w", pat, path, err)
+			}
+		}
+	}
+	if c.Policy != nil {
+		for _, e := range slices.Concat(c.Policy.Allow, c.Policy.Deny) {
+			if _, err := matchPolicyPattern(e.Pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in policy of config file %q: %w", e.Pattern, path, err)
+			}
+		}
+	}
+	for _, os := range c.RunnerOS {
+		if !slices.Contains(RunnerOSNames, os) {
+			return nil, fmt.Errorf("invalid runner OS %q in runner-os of config file %q. it must be one of [%s]", os, path, strings.Join(RunnerOSNames, ","))
+		}
+	}
+	return &c, nil
+}
+
+// ReadConfigFile reads the configuration file at path.
+func ReadConfigFile(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file %q: %w", path, err)
+	}
+	return parseConfig(b, path)
+}
+
+// findConfig reads the configuration file at one of the default locations. It
+// returns nil if there is none.
+func findConfig() (*Config, error) {
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return ReadConfigFile(path)
+	}
+	return nil, nil
+}
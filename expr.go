@@ -1,5 +1,93 @@
 package compositeactionlint
 
-import "github.com/rhysd/actionlint"
+import (
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
 
 type ExprError = actionlint.ExprError
+
+// visitExprsIn calls f with each ${{ }} expression in s and the byte offset
+// of the expression in s. Expressions with syntax errors, which are reported
+// by the expression rule, stop the visit.
+func visitExprsIn(s string, f func(expr ExprNode, offset int)) {
+	offset := 0
+	for {
+		idx := strings.Index(s, "${{")
+		if idx == -1 {
+			return
+		}
+		start := idx + 3
+		s = s[start:]
+		offset += start
+
+		l := actionlint.NewExprLexer(s)
+		expr, err := actionlint.NewExprParser().Parse(l)
+		if err != nil {
+			return
+		}
+		f(expr, offset)
+
+		s = s[l.Offset():]
+		offset += l.Offset()
+	}
+}
+
+// conditionString returns the `if:` condition s with the ${{ }} GitHub allows
+// to omit, so that it can be visited like other strings.
+func conditionString(s *String) *String {
+	if s == nil || s.ContainsExpression() {
+		return s
+	}
+	return &String{Value: "${{" + s.Value + "}}", Quoted: s.Quoted, Pos: &Pos{Line: s.Pos.Line, Col: s.Pos.Col - 3}}
+}
+
+// compositeStepStrings lists the values of a composite step in which GitHub
+// evaluates expressions. id, shell and uses are used literally.
+func compositeStepStrings(n *Step) []*String {
+	ss := []*String{n.Name, conditionString(n.If)}
+	for _, e := range n.Env {
+		ss = append(ss, e.Value)
+	}
+	if n.ContinueOnError != nil {
+		ss = append(ss, n.ContinueOnError.Expression)
+	}
+	switch e := n.Exec.(type) {
+	case *actionlint.ExecRun:
+		ss = append(ss, e.Run, e.WorkingDirectory)
+	case *actionlint.ExecAction:
+		for _, i := range e.Inputs {
+			ss = append(ss, i.Value)
+		}
+	}
+	return ss
+}
+
+// visitContextRefs calls f with the lower case property of each access of
+// the context named ctx in the expressions in s, like "x" for ctx.x and
+// ctx['x'], and the byte offset of the access in s. The property is "" when
+// the context is used as a whole, like in toJSON(ctx) or ctx[matrix.key].
+func visitContextRefs(s *String, ctx string, f func(prop string, offset int)) {
+	if s == nil {
+		return
+	}
+	visitExprsIn(s.Value, func(expr ExprNode, offset int) {
+		actionlint.VisitExprNode(expr, func(n, parent ExprNode, entering bool) {
+			if v, ok := n.(*actionlint.VariableNode); !entering || !ok || !strings.EqualFold(v.Name, ctx) {
+				return
+			}
+			switch p := parent.(type) {
+			case *actionlint.ObjectDerefNode:
+				f(strings.ToLower(p.Property), offset+n.Token().Offset)
+				return
+			case *actionlint.IndexAccessNode:
+				if idx, ok := p.Index.(*actionlint.StringNode); ok && p.Operand == n {
+					f(strings.ToLower(idx.Value), offset+n.Token().Offset)
+					return
+				}
+			}
+			f("", offset+n.Token().Offset)
+		})
+	})
+}
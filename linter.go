@@ -4,10 +4,35 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+
+	"github.com/rhysd/actionlint"
 )
 
 type Linter struct {
 	out io.Writer
+	// config is the configuration of the rules, nil when there is none.
+	config *Config
+	// checkCallers enables checking calls of local composite actions in
+	// workflow files against the metadata of the actions.
+	checkCallers bool
+	// workflows lints workflow files passed alongside action metadata. It is
+	// created on first use.
+	workflows *actionlint.Linter
+	// callers checks calls of local actions. It is created on first use.
+	callers *callerChecker
+	// actions caches metadata of local actions used by the linted files.
+	actions *localActionCache
+	// graph collects the linted actions to find cycles and deep nesting
+	// among them once all files are linted.
+	graph *actionGraph
+}
+
+func (l *Linter) localActions() *localActionCache {
+	if l.actions == nil {
+		l.actions = newLocalActionCache()
+	}
+	return l.actions
 }
 
 func (l *Linter) LintFiles(paths []string) ([]*Error, error) {
@@ -19,6 +44,15 @@ func (l *Linter) LintFiles(paths []string) ([]*Error, error) {
 		}
 		all = append(all, errs...)
 	}
+
+	if l.graph != nil {
+		errs := l.graph.check()
+		for _, err := range errs {
+			src, _ := os.ReadFile(err.Filepath)
+			err.PrettyPrint(l.out, src)
+		}
+		all = append(all, errs...)
+	}
 	return all, nil
 }
 
@@ -27,19 +61,105 @@ func (l *Linter) LintFile(path string) ([]*Error, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not read %q: %w", path, err)
 	}
+	if isWorkflow(content) {
+		return l.lintWorkflow(path, content)
+	}
 	errs, err := l.check(path, content)
 
 	l.printErrors(errs, content)
 	return errs, err
 }
 
+// lintWorkflow checks a workflow file with actionlint and, if enabled, the
+// calls of local composite actions in it.
+func (l *Linter) lintWorkflow(path string, content []byte) ([]*Error, error) {
+	if l.workflows == nil {
+		// Errors are printed by us so they can be merged with ours. Like the
+		// actionlint command, scripts are checked with shellcheck and
+		// pyflakes, which actionlint skips when they are not installed
+		w, err := actionlint.NewLinter(io.Discard, &actionlint.LinterOptions{
+			Shellcheck: "shellcheck",
+			Pyflakes:   "pyflakes",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create actionlint linter: %w", err)
+		}
+		l.workflows = w
+	}
+
+	// Our expression rule replaces actionlint's special function
+	// availability with the one of action metadata.
+	RestoreSpecialFunctionNames()
+	errs, err := l.workflows.Lint(path, content, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.checkCallers {
+		if l.callers == nil {
+			l.callers = newCallerChecker(l.localActions())
+		}
+		errs = mergeErrors(errs, l.callers.check(path, content))
+	}
+
+	l.printErrors(errs, content)
+	return errs, nil
+}
+
+// mergeErrors merges our errors into the ones of actionlint, which knows about
+// local actions as well. Where both report a problem at the same position
+// only ours is kept.
+func mergeErrors(theirs, ours []*Error) []*Error {
+	all := slices.DeleteFunc(theirs, func(t *Error) bool {
+		return slices.ContainsFunc(ours, func(o *Error) bool {
+			return o.Line == t.Line && o.Column == t.Column
+		})
+	})
+	all = append(all, ours...)
+	sortErrors(all)
+	return all
+}
+
+// sortErrors sorts errors of a file by their position, keeping the order of
+// errors at the same position.
+func sortErrors(errs []*Error) {
+	slices.SortStableFunc(errs, func(a, b *Error) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+}
+
 func (l *Linter) check(path string, content []byte) ([]*Error, error) {
 
 	a, all := Parse(content)
 
 	if a != nil {
+		var runnerOS []string
+		if l.config != nil {
+			runnerOS = l.config.RunnerOS
+		}
 		rules := []Rule{
-			NewRuleExpression(),
+			NewRuleExpression(content),
+			NewRuleUses(),
+			NewRuleLocalUses(path),
+			NewRuleActionPath(path, content),
+			NewRuleFiles(path, content),
+			NewRuleShell(runnerOS),
+			NewRuleStepID(),
+			NewRuleLiteral(),
+			NewRuleUnusedInput(),
+			NewRuleConditionalOutput(content),
+			NewRuleEnvVar(),
+			NewRuleStepEnv(content),
+			NewRuleDeprecatedCommand(content),
+		}
+		if l.config != nil && l.config.Pinning != nil {
+			rules = append(rules, NewRulePinning(l.config.Pinning))
+		}
+		if l.config != nil && l.config.Policy != nil {
+			rules = append(rules, NewRulePolicy(l.config.Policy))
 		}
 
 		v := Visitor{}
@@ -53,8 +173,14 @@ func (l *Linter) check(path string, content []byte) ([]*Error, error) {
 			errs := rule.Errs()
 			all = append(all, errs...)
 		}
+
+		if l.graph == nil {
+			l.graph = newActionGraph(l.localActions())
+		}
+		l.graph.add(path, a)
 	}
 
+	sortErrors(all)
 	for _, err := range all {
 		err.Filepath = path
 	}
@@ -0,0 +1,129 @@
+package compositeactionlint
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// workspaceRoot guesses the directory `uses: ./...` is relative to for the
+// workflow or action metadata file at path, which is the root of its
+// repository: the parent of the enclosing .github directory or the closest
+// directory containing .git. It falls back to the current directory.
+func workspaceRoot(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "."
+	}
+	for dir := filepath.Dir(abs); ; {
+		if filepath.Base(dir) == ".github" {
+			return filepath.Dir(dir)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "."
+		}
+		dir = parent
+	}
+}
+
+// isPublishedAction reports whether the action with metadata file at path
+// looks like it is published for use from other repositories: it is at the
+// root of its repository or outside of .github, where actions only used by
+// the repository's own workflows usually live.
+func isPublishedAction(path string) bool {
+	root := workspaceRoot(path)
+	if root == "." {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(abs))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".github" && !strings.HasPrefix(filepath.ToSlash(rel), ".github/"))
+}
+
+var githubRemoteRe = regexp.MustCompile(`github\.com[:/]([^/\s]+)/([^/\s]+?)(?:\.git)?/?$`)
+
+// repositoryName returns "{owner}/{repo}" of the repository at root from the
+// URL of its "origin" remote on GitHub, or "" if it cannot be found.
+func repositoryName(root string) string {
+	b, err := os.ReadFile(filepath.Join(root, ".git", "config"))
+	if err != nil {
+		return ""
+	}
+	inOrigin := false
+	for _, l := range strings.Split(string(b), "\n") {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "[") {
+			inOrigin = l == `[remote "origin"]`
+			continue
+		}
+		k, v, ok := strings.Cut(l, "=")
+		if !inOrigin || !ok || strings.TrimSpace(k) != "url" {
+			continue
+		}
+		if ss := githubRemoteRe.FindStringSubmatch(strings.TrimSpace(v)); ss != nil {
+			return ss[1] + "/" + ss[2]
+		}
+	}
+	return ""
+}
+
+type localAction struct {
+	// meta is nil when the metadata could not be read or has errors.
+	meta  *ActionMetadata
+	found bool
+	err   error
+}
+
+// localActionCache reads and parses metadata of local actions once per
+// directory.
+type localActionCache struct {
+	actions map[string]*localAction
+}
+
+func newLocalActionCache() *localActionCache {
+	return &localActionCache{actions: map[string]*localAction{}}
+}
+
+// add records the metadata of an action which has been linted already.
+func (c *localActionCache) add(dir string, meta *ActionMetadata) {
+	c.actions[dir] = &localAction{meta: meta, found: true}
+}
+
+// find returns the metadata of the action in dir. found is false when dir
+// contains neither action.yml nor action.yaml. Metadata with problems is
+// returned as nil since those are reported when linting the action itself.
+func (c *localActionCache) find(dir string) (meta *ActionMetadata, found bool, err error) {
+	if a, ok := c.actions[dir]; ok {
+		return a.meta, a.found, a.err
+	}
+
+	a := &localAction{}
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		a.found = true
+		if err != nil {
+			a.err = err
+		} else if m, errs := Parse(b); len(errs) == 0 {
+			a.meta = m
+		}
+		break
+	}
+	c.actions[dir] = a
+	return a.meta, a.found, a.err
+}
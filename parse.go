@@ -1,9 +1,10 @@
 package compositeactionlint
 
 import (
+	"bytes"
 	"fmt"
-	"regexp"
-	"strconv"
+	"io"
+	"slices"
 	"strings"
 
 	"github.com/rhysd/actionlint"
@@ -13,7 +14,7 @@ import (
 func nodeKindName(kind yaml.Kind) string {
 	switch kind {
 	case yaml.AliasNode:
-		return "mapping"
+		return "alias"
 	case yaml.DocumentNode:
 		return "document"
 	case yaml.MappingNode:
@@ -62,6 +63,38 @@ func (p *parser) errorfAt(pos *Pos, format string, args ...any) {
 	p.errorAt(pos, fmt.Sprintf(format, args...))
 }
 
+// deref resolves n when it is an alias to an anchored node. Positions of the
+// resolved node point at the anchor, so errors reported until the returned
+// function is called are annotated with the position of the alias as well.
+func (p *parser) deref(n *yaml.Node) (*yaml.Node, func()) {
+	if n.Kind != yaml.AliasNode || n.Alias == nil {
+		return n, func() {}
+	}
+	alias := n
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	start := len(p.errors)
+	return n, func() {
+		errs := p.errors[start:]
+		p.errors = p.errors[:start]
+		for _, err := range errs {
+			// The anchored node was usually parsed where it is defined already
+			if slices.ContainsFunc(p.errors[:start], func(e *Error) bool {
+				return e.Line == err.Line && e.Column == err.Column && e.Message == err.Message
+			}) {
+				continue
+			}
+			sep := ". "
+			if strings.HasSuffix(err.Message, "?") {
+				sep = " "
+			}
+			err.Message += fmt.Sprintf("%snote that this was reached through alias \"*%s\" at %s", sep, alias.Value, posAt(alias).String())
+			p.errors = append(p.errors, err)
+		}
+	}
+}
+
 func (p *parser) unexpectedKey(s *String, sec string, expected []string) {
 	l := len(expected)
 	var m string
@@ -72,6 +105,9 @@ func (p *parser) unexpectedKey(s *String, sec string, expected []string) {
 	} else {
 		m = fmt.Sprintf("unexpected key %q for %q section", s.Value, sec)
 	}
+	if c := closestMatch(s.Value, expected); c != "" {
+		m += fmt.Sprintf(". did you mean %q?", c)
+	}
 	p.errorAt(s.Pos, m)
 }
 
@@ -84,6 +120,9 @@ func (p *parser) checkNotEmpty(sec string, len int, n *yaml.Node) bool {
 }
 
 func (p *parser) checkSequence(sec string, n *yaml.Node, allowEmpty bool) bool {
+	n, done := p.deref(n)
+	defer done()
+
 	if n.Kind != yaml.SequenceNode {
 		p.errorf(n, "%q section must be sequence node but got %s node with %q tag", sec, nodeKindName(n.Kind), n.Tag)
 		return false
@@ -92,6 +131,9 @@ func (p *parser) checkSequence(sec string, n *yaml.Node, allowEmpty bool) bool {
 }
 
 func (p *parser) checkString(n *yaml.Node, allowEmpty bool) bool {
+	n, done := p.deref(n)
+	defer done()
+
 	if n.Kind != yaml.ScalarNode {
 		p.errorf(n, "expected string but found %q node", nodeKindName(n.Kind))
 		return false
@@ -120,10 +162,14 @@ func (p *parser) parseString(n *yaml.Node, allowEmpty bool) *String {
 	if !p.checkString(n, allowEmpty) {
 		return &String{Value: "", Quoted: false, Pos: posAt(n)}
 	}
+	n, _ = p.deref(n)
 	return newString(n)
 }
 
 func (p *parser) parseBool(n *yaml.Node) *Bool {
+	n, done := p.deref(n)
+	defer done()
+
 	if n.Kind != yaml.ScalarNode || (n.Tag != "!!bool" && n.Tag != "!!str") {
 		p.errorf(n, "expected bool value but found %s node with %q tag", nodeKindName(n.Kind), n.Tag)
 		return nil
@@ -144,6 +190,9 @@ func (p *parser) parseBool(n *yaml.Node) *Bool {
 }
 
 func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty bool, caseSensitive bool) []workflowKeyVal {
+	n, done := p.deref(n)
+	defer done()
+
 	isNull := isNull(n)
 
 	if !isNull && n.Kind != yaml.MappingNode {
@@ -156,11 +205,11 @@ func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty bool, caseSe
 		return nil
 	}
 
-	l := len(n.Content) / 2
-	keys := make(map[string]*Pos, l)
-	m := make([]workflowKeyVal, 0, l)
-	for i := 0; i < len(n.Content); i += 2 {
-		k := p.parseString(n.Content[i], false)
+	entries := p.mappingEntries(n)
+	keys := make(map[string]*Pos, len(entries))
+	m := make([]workflowKeyVal, 0, len(entries))
+	for _, e := range entries {
+		k := p.parseString(e.key, false)
 		if k == nil {
 			continue
 		}
@@ -173,6 +222,10 @@ func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty bool, caseSe
 		}
 
 		if pos, ok := keys[id]; ok {
+			if e.merged {
+				// Keys of the mapping itself override merged keys
+				continue
+			}
 			var note string
 			if !caseSensitive {
 				note = ". note that this key is case insensitive"
@@ -180,7 +233,7 @@ func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty bool, caseSe
 			p.errorfAt(k.Pos, "key %q is duplicated in %s. previously defined at %s%s", k.Value, what, pos.String(), note)
 			continue
 		}
-		m = append(m, workflowKeyVal{id, k, n.Content[i+1]})
+		m = append(m, workflowKeyVal{id, k, e.val})
 		keys[id] = k.Pos
 	}
 
@@ -191,8 +244,72 @@ func (p *parser) parseMapping(what string, n *yaml.Node, allowEmpty bool, caseSe
 	return m
 }
 
+const compositeStepsDocsURL = "https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runssteps"
+
+// workflowOnlyStepKeys are keys of workflow steps and jobs which people porting
+// a job to a composite action tend to keep, with the reason they do not apply.
+var workflowOnlyStepKeys = map[string]string{
+	"timeout-minutes": "the timeout of the job calling the action applies to its steps",
+	"runs-on":         "steps run on the runner of the job calling the action",
+	"needs":           "steps run in the order they are defined in",
+	"strategy":        "the action runs once per call. use a matrix in the job calling the action instead",
+	"matrix":          "the action runs once per call. use a matrix in the job calling the action instead",
+	"permissions":     "permissions of the GITHUB_TOKEN are set by the workflow calling the action",
+	"environment":     "deployment environments are set by the job calling the action",
+	"concurrency":     "concurrency is controlled by the workflow calling the action",
+	"container":       "steps run in the environment of the job calling the action",
+	"services":        "service containers are started by the job calling the action",
+	"defaults":        "set \"shell\" and \"working-directory\" on each step instead",
+	"outputs":         "declare outputs in the top-level \"outputs\" section of the action instead",
+	"secrets":         "secrets are not available to actions. pass them as inputs instead",
+	"steps":           "steps cannot be nested. use another composite action with \"uses\" instead",
+}
+
+type mappingEntry struct {
+	key    *yaml.Node
+	val    *yaml.Node
+	merged bool
+}
+
+// mappingEntries lists the keys and values of mapping n, followed by the ones
+// merged into it with merge keys ("<<: *anchor").
+func (p *parser) mappingEntries(n *yaml.Node) []mappingEntry {
+	entries := make([]mappingEntry, 0, len(n.Content)/2)
+	merged := []mappingEntry{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind != yaml.ScalarNode || k.Tag != "!!merge" {
+			entries = append(entries, mappingEntry{k, v, false})
+			continue
+		}
+
+		p.error(k, "merge key \"<<\" is not supported by GitHub Actions. only anchors and aliases of whole nodes are")
+
+		srcs := []*yaml.Node{v}
+		if v.Kind == yaml.SequenceNode {
+			srcs = v.Content
+		}
+		for _, src := range srcs {
+			src, done := p.deref(src)
+			if src.Kind != yaml.MappingNode {
+				p.errorf(src, "value of merge key \"<<\" must be an alias to a mapping but got %s node", nodeKindName(src.Kind))
+			} else {
+				for _, e := range p.mappingEntries(src) {
+					merged = append(merged, mappingEntry{e.key, e.val, true})
+				}
+			}
+			done()
+		}
+	}
+	return append(entries, merged...)
+}
+
 func (p *parser) parseStep(n *yaml.Node) *Step {
 	ret := &Step{Pos: posAt(n)}
+	alias := n.Kind == yaml.AliasNode
+
+	n, done := p.deref(n)
+	defer done()
 
 	run := &actionlint.ExecRun{}
 	action := &actionlint.ExecAction{}
@@ -243,6 +360,10 @@ func (p *parser) parseStep(n *yaml.Node) *Step {
 		case "working-directory":
 			run.WorkingDirectory = p.parseString(v, false)
 		default:
+			if why, ok := workflowOnlyStepKeys[kv.id]; ok {
+				p.errorfAt(k.Pos, "%q is not supported in composite action steps since it is a key of workflow jobs or steps. %s. see %s", k.Value, why, compositeStepsDocsURL)
+				continue
+			}
 			p.unexpectedKey(k, "step", []string{
 				"if",
 				"id",
@@ -278,10 +399,21 @@ func (p *parser) parseStep(n *yaml.Node) *Step {
 	} else {
 		p.error(n, "step missing both \"run\" and \"uses\"")
 	}
+
+	// The ID identifies this step rather than the anchored one, so rules
+	// report it at the alias
+	if alias && ret.ID != nil {
+		id := *ret.ID
+		id.Pos = ret.Pos
+		ret.ID = &id
+	}
 	return ret
 }
 
 func (p *parser) parseSteps(n *yaml.Node) []*Step {
+	n, done := p.deref(n)
+	defer done()
+
 	if ok := p.checkSequence("steps", n, false); !ok {
 		return nil
 	}
@@ -299,6 +431,10 @@ func (p *parser) parseSteps(n *yaml.Node) []*Step {
 
 func (p *parser) parseOutput(id *String, n *yaml.Node) *Output {
 	ret := &Output{ID: id}
+
+	n, done := p.deref(n)
+	defer done()
+
 	for _, kv := range p.parseMapping("output", n, false, true) {
 		k, v := kv.key, kv.val
 		switch kv.id {
@@ -337,8 +473,12 @@ func (p *parser) parseOutputs(n *yaml.Node) map[string]*Output {
 
 func (p *parser) parseInput(id *String, n *yaml.Node) *Input {
 	i := &Input{ID: id, Pos: id.Pos}
+
+	n, done := p.deref(n)
+	defer done()
+
 	for _, kv := range p.parseMapping("input", n, false, true) {
-		_, v := kv.key, kv.val
+		k, v := kv.key, kv.val
 		switch kv.id {
 		case "description":
 			i.Description = p.parseString(v, false)
@@ -348,6 +488,13 @@ func (p *parser) parseInput(id *String, n *yaml.Node) *Input {
 			i.Default = p.parseString(v, true)
 		case "deprecationMessage":
 			i.DeprecationMessage = p.parseString(v, false)
+		default:
+			p.unexpectedKey(k, "input", []string{
+				"description",
+				"required",
+				"default",
+				"deprecationMessage",
+			})
 		}
 	}
 	if i.Description == nil {
@@ -370,14 +517,44 @@ func (p *parser) parseInputs(n *yaml.Node) map[string]*Input {
 func (p *parser) parseRuns(pos *Pos, n *yaml.Node) *Runs {
 	ret := &Runs{}
 	var stepsPos *Pos
+
+	n, done := p.deref(n)
+	defer done()
+
 	for _, kv := range p.parseMapping("runs section", n, false, true) {
-		_, v := kv.key, kv.val
+		k, v := kv.key, kv.val
 		switch kv.id {
 		case "using":
 			ret.Using = p.parseString(v, false)
 		case "steps":
 			ret.Steps = p.parseSteps(v)
 			stepsPos = kv.key.Pos
+		case "main":
+			ret.Main = p.parseString(v, false)
+		case "pre":
+			ret.Pre = p.parseString(v, false)
+		case "post":
+			ret.Post = p.parseString(v, false)
+		case "image":
+			ret.Image = p.parseString(v, false)
+		case "pre-if", "post-if", "env", "entrypoint", "pre-entrypoint", "post-entrypoint", "args":
+			// Keys of JavaScript and Docker actions. Not parsed
+		default:
+			p.unexpectedKey(k, "runs", []string{
+				"using",
+				"steps",
+				"main",
+				"pre",
+				"pre-if",
+				"post",
+				"post-if",
+				"image",
+				"env",
+				"entrypoint",
+				"pre-entrypoint",
+				"post-entrypoint",
+				"args",
+			})
 		}
 	}
 
@@ -413,6 +590,11 @@ func (p *parser) parse(n *yaml.Node) *ActionMetadata {
 		return a
 	}
 
+	if k := workflowKey(n.Content[0]); k != nil {
+		p.errorf(k, "this looks like a workflow file rather than action metadata since it has top-level %q key. workflow files are checked with actionlint (https://github.com/rhysd/actionlint) instead of being parsed as action metadata", k.Value)
+		return nil
+	}
+
 	for _, kv := range p.parseMapping("action metadata", n.Content[0], false, true) {
 		k, v := kv.key, kv.val
 		switch kv.id {
@@ -428,6 +610,8 @@ func (p *parser) parse(n *yaml.Node) *ActionMetadata {
 			a.Outputs = p.parseOutputs(v)
 		case "runs":
 			a.Runs = p.parseRuns(k.Pos, v)
+		case "branding":
+			// Not parsed
 		default:
 			p.unexpectedKey(k, "action metadata", []string{
 				"name",
@@ -457,37 +641,55 @@ func (p *parser) parse(n *yaml.Node) *ActionMetadata {
 	return a
 }
 
-func handleYAMLError(err error) []*Error {
-	re := regexp.MustCompile(`\bline (\d+):`)
-
-	yamlErr := func(msg string) *Error {
-		l := 0
-		if ss := re.FindStringSubmatch(msg); len(ss) > 1 {
-			l, _ = strconv.Atoi(ss[1])
-		}
-		msg = fmt.Sprintf("could not parse as YAML: %s", msg)
-		return newError(msg, "", l, 0, "syntax-check")
+// workflowKey returns the top-level "on" or "jobs" key of n if it is the
+// root of a workflow file rather than action metadata.
+func workflowKey(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
 	}
-
-	if te, ok := err.(*yaml.TypeError); ok {
-		errs := make([]*Error, 0, len(te.Errors))
-		for _, msg := range te.Errors {
-			errs = append(errs, yamlErr(msg))
+	var found *yaml.Node
+	for i := 0; i < len(n.Content); i += 2 {
+		k := n.Content[i]
+		switch k.Value {
+		case "runs":
+			return nil
+		case "on", "jobs":
+			if found == nil {
+				found = k
+			}
 		}
-		return errs
 	}
+	return found
+}
 
-	return []*Error{yamlErr(err.Error())}
+// isWorkflow reports whether the first document of b looks like a workflow
+// file rather than action metadata. Files which are not valid YAML are
+// treated as action metadata so the parser can report the error.
+func isWorkflow(b []byte) bool {
+	var n yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&n); err != nil || len(n.Content) == 0 {
+		return false
+	}
+	return workflowKey(n.Content[0]) != nil
 }
 
 func Parse(b []byte) (*ActionMetadata, []*Error) {
 	var n yaml.Node
 
-	if err := yaml.Unmarshal(b, &n); err != nil {
-		return nil, handleYAMLError(err)
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	if err := d.Decode(&n); err != nil && err != io.EOF {
+		return nil, handleYAMLError(err, b)
 	}
 
 	p := &parser{}
+
+	var next yaml.Node
+	if err := d.Decode(&next); err == nil {
+		p.error(&next, "action metadata file must contain a single YAML document. GitHub only reads the first one, so merge or remove the documents after this \"---\" separator")
+	} else if err != io.EOF {
+		return nil, handleYAMLError(err, b)
+	}
+
 	w := p.parse(&n)
 
 	return w, p.errors
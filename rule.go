@@ -17,6 +17,18 @@ func (r *RuleBase) Name() string { return r.name }
 // Description returns the description of the rule.
 func (r *RuleBase) Description() string { return r.desc }
 
+// VisitStep is a callback called when visiting a step. By default it does
+// nothing.
+func (r *RuleBase) VisitStep(node *Step) error { return nil }
+
+// VisitActionMetadataPre is called before visiting the steps. By default it
+// does nothing.
+func (r *RuleBase) VisitActionMetadataPre(node *ActionMetadata) error { return nil }
+
+// VisitActionMetadataPost is called after visiting the steps. By default it
+// does nothing.
+func (r *RuleBase) VisitActionMetadataPost(node *ActionMetadata) error { return nil }
+
 func (r *RuleBase) Errorf(pos *Pos, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	r.errs = append(r.errs, newError(msg, "", pos.Line, pos.Col, r.name))
@@ -0,0 +1,162 @@
+package compositeactionlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// scriptInterpreters are commands whose first argument is a script to run.
+var scriptInterpreters = map[string]bool{"source": true, ".": true, "bash": true, "sh": true}
+
+// scriptKeywords are words after which a command follows.
+var scriptKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "while": true, "until": true,
+	"do": true, "exec": true, "time": true, "!": true,
+}
+
+// RuleActionPath reports relative paths in `run:` and `working-directory:`
+// which refer to files of the action. Steps of composite actions run in the
+// workspace of the calling workflow, so such files have to be referred to
+// through ${{ github.action_path }}.
+type RuleActionPath struct {
+	RuleBase
+	src []string
+	// dir is the directory of the action.
+	dir string
+	// root is the workspace the action's steps run in when it is used from
+	// its own repository.
+	root string
+	// published is whether the action is used from other repositories, where
+	// files of the action are never in the workspace.
+	published bool
+}
+
+// NewRuleActionPath creates the rule for the action metadata file at path
+// with source src.
+func NewRuleActionPath(path string, src []byte) *RuleActionPath {
+	rule := &RuleActionPath{
+		RuleBase: RuleBase{
+			name: "action-path",
+			desc: "Checks files of the action are referred to through github.action_path",
+		},
+		src:       sourceLines(src),
+		root:      workspaceRoot(path),
+		published: isPublishedAction(path),
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		rule.dir = filepath.Dir(abs)
+	}
+	return rule
+}
+
+func (rule *RuleActionPath) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecRun)
+	if !ok || rule.dir == "" {
+		return nil
+	}
+
+	if e.Run != nil {
+		posAt := stringPos(rule.src, e.Run)
+		for _, m := range scriptCommandWords(e.Run.Value) {
+			w := e.Run.Value[m[0]:m[1]]
+			// Only words which look like paths rather than command names
+			if !strings.HasPrefix(w, "./") && !strings.Contains(w, "/") {
+				continue
+			}
+			if rel, ok := rule.actionFile(w); ok {
+				pos := posAt(m[0])
+				rule.Errorf(&pos, "%q is resolved against the workspace of the calling workflow, not the directory of this action where it exists. use \"${{ github.action_path }}/%s\" instead", w, rel)
+			}
+		}
+	}
+
+	if wd := e.WorkingDirectory; wd != nil {
+		if rel, ok := rule.actionFile(wd.Value); ok {
+			rule.Errorf(wd.Pos, "working directory %q is resolved against the workspace of the calling workflow, not the directory of this action where it exists. use \"${{ github.action_path }}/%s\" instead", wd.Value, rel)
+		}
+	}
+	return nil
+}
+
+// actionFile reports whether the relative path p refers to a file beside the
+// action which the step cannot find in its workspace. It returns p cleaned.
+func (rule *RuleActionPath) actionFile(p string) (string, bool) {
+	if p == "" || strings.ContainsAny(p[:1], "-/$~") || strings.Contains(p, "${{") || strings.Contains(p, "=") {
+		return "", false
+	}
+	rel := filepath.ToSlash(filepath.Clean(p))
+	if rel == "." || strings.HasPrefix(rel, "../") || rel == ".." {
+		return "", false
+	}
+
+	if _, err := os.Stat(filepath.Join(rule.dir, rel)); err != nil {
+		return "", false
+	}
+	if !rule.published {
+		if _, err := os.Stat(filepath.Join(rule.root, rel)); err == nil {
+			return "", false
+		}
+	}
+	return rel, true
+}
+
+// scriptCommandWords returns the offsets of the words of script which are run
+// as commands, or as scripts by source, ".", bash or sh, roughly following
+// shell syntax. Quoted text and comments are skipped.
+func scriptCommandWords(script string) [][2]int {
+	words := [][2]int{}
+	// cmd is set when the next word is a command, interp when it is the
+	// script of an interpreter and redirect when it is a redirection target
+	cmd, interp, redirect := true, false, false
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '\n' || strings.IndexByte(";&|()", c) >= 0:
+			cmd, interp, redirect = true, false, false
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' && (i == 0 || strings.IndexByte(" \t\n", script[i-1]) >= 0):
+			if j := strings.IndexByte(script[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"' || c == '`':
+			if j := strings.IndexByte(script[i+1:], c); j >= 0 {
+				i += j + 2
+			} else {
+				i = len(script)
+			}
+			cmd, interp, redirect = false, false, false
+		case c == '<' || c == '>':
+			redirect = true
+			i++
+		default:
+			j := i
+			for j < len(script) && strings.IndexByte(" \t\r\n;&|()<>'\"`", script[j]) < 0 {
+				j++
+			}
+			w := script[i:j]
+			switch {
+			case redirect:
+				redirect = false
+			case cmd && scriptKeywords[w]:
+				// The command follows
+			case cmd && strings.Contains(w, "=") && !strings.HasPrefix(w, "="):
+				// Assignment before the command
+			case cmd:
+				words = append(words, [2]int{i, j})
+				cmd, interp = false, scriptInterpreters[w]
+			case interp && !strings.HasPrefix(w, "-"):
+				words = append(words, [2]int{i, j})
+				interp = false
+			}
+			i = j
+		}
+	}
+	return words
+}
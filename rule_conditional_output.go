@@ -0,0 +1,143 @@
+package compositeactionlint
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// alwaysRunConditions are conditions which do not skip a step when the
+// steps before it succeeded.
+var alwaysRunConditions = map[string]bool{
+	"true":         true,
+	"always()":     true,
+	"success()":    true,
+	"!cancelled()": true,
+}
+
+// RuleConditionalOutput reports outputs of steps with an `if:` condition
+// used without a fallback by the outputs of the action or by later steps
+// which always run. The outputs of a skipped step are empty.
+type RuleConditionalOutput struct {
+	RuleBase
+	src []string
+	// conds are the conditions of the conditional steps visited so far by
+	// the lower case ID of the step.
+	conds map[string]*String
+}
+
+// NewRuleConditionalOutput creates the rule for the action metadata with
+// source src.
+func NewRuleConditionalOutput(src []byte) *RuleConditionalOutput {
+	return &RuleConditionalOutput{
+		RuleBase: RuleBase{
+			name: "conditional-output",
+			desc: "Checks outputs of conditional steps are not used without a fallback",
+		},
+		src: sourceLines(src),
+	}
+}
+
+func (rule *RuleConditionalOutput) VisitActionMetadataPre(n *ActionMetadata) error {
+	rule.conds = map[string]*String{}
+	return nil
+}
+
+func (rule *RuleConditionalOutput) VisitStep(n *Step) error {
+	if isConditional(n.If) {
+		// A step with its own condition likely runs only when the steps it
+		// depends on did
+		if n.ID != nil && !n.ID.ContainsExpression() {
+			rule.conds[strings.ToLower(n.ID.Value)] = n.If
+		}
+		return nil
+	}
+	for _, s := range compositeStepStrings(n) {
+		rule.check(s, "this step")
+	}
+	return nil
+}
+
+func (rule *RuleConditionalOutput) VisitActionMetadataPost(n *ActionMetadata) error {
+	outputs := make([]*Output, 0, len(n.Outputs))
+	for _, o := range n.Outputs {
+		outputs = append(outputs, o)
+	}
+	slices.SortFunc(outputs, func(a, b *Output) int {
+		return cmp.Or(cmp.Compare(a.ID.Pos.Line, b.ID.Pos.Line), cmp.Compare(a.ID.Pos.Col, b.ID.Pos.Col))
+	})
+	for _, o := range outputs {
+		rule.check(o.Value, "output \""+o.ID.Value+"\" of the action")
+	}
+	return nil
+}
+
+// check reports expressions in s which use only outputs of conditional steps
+// and have no `||` fallback for them. what names the user of s.
+func (rule *RuleConditionalOutput) check(s *String, what string) {
+	if s == nil || len(rule.conds) == 0 {
+		return
+	}
+	posAt := stringPos(rule.src, s)
+	visitExprsIn(s.Value, func(expr ExprNode, offset int) {
+		parents := map[al.ExprNode]al.ExprNode{}
+		var first al.ExprNode
+		var id, output string
+		onlyConditional := true
+		al.VisitExprNode(expr, func(n, parent al.ExprNode, entering bool) {
+			if !entering {
+				return
+			}
+			parents[n] = parent
+			i, o, ok := stepOutputRef(n)
+			if !ok {
+				return
+			}
+			if _, ok := rule.conds[strings.ToLower(i)]; !ok {
+				onlyConditional = false
+				return
+			}
+			if first == nil && !hasFallback(n, parents) {
+				first, id, output = n, i, o
+			}
+		})
+		if first == nil || !onlyConditional {
+			return
+		}
+
+		cond := rule.conds[strings.ToLower(id)]
+		pos := posAt(offset + first.Token().Offset)
+		rule.Errorf(&pos, "%s depends only on \"steps.%s.outputs.%s\", which is empty when step %q is skipped by its condition at %s. add a fallback like \"${{ steps.%s.outputs.%s || '<default>' }}\"", what, id, output, id, cond.Pos.String(), id, output)
+	})
+}
+
+// hasFallback reports whether n is on the left side of an `||`, directly or
+// through other `||`, so that its value is replaced when it is empty.
+// parents maps the nodes visited so far to their parents.
+func hasFallback(n al.ExprNode, parents map[al.ExprNode]al.ExprNode) bool {
+	for {
+		op, ok := parents[n].(*al.LogicalOpNode)
+		if !ok || op.Kind != al.LogicalOpNodeKindOr {
+			return false
+		}
+		if op.Left == n {
+			return true
+		}
+		n = op
+	}
+}
+
+// isConditional reports whether a step with condition cond may be skipped
+// although the steps before it succeeded.
+func isConditional(cond *String) bool {
+	if cond == nil {
+		return false
+	}
+	c := strings.TrimSpace(cond.Value)
+	if strings.HasPrefix(c, "${{") && strings.HasSuffix(c, "}}") && strings.Count(c, "${{") == 1 {
+		c = strings.TrimSpace(c[3 : len(c)-2])
+	}
+	return !alwaysRunConditions[strings.ReplaceAll(c, " ", "")]
+}
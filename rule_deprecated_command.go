@@ -0,0 +1,71 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"regexp"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// deprecatedCommandRe matches the workflow commands replaced by environment
+// files, with the name parameter of the command if any.
+// https://github.blog/changelog/2022-10-11-github-actions-deprecating-save-state-and-set-output-commands/
+var deprecatedCommandRe = regexp.MustCompile(`::(set-output|save-state|set-env|add-path)(?:\s+name=([^:\s]+))?\s*::`)
+
+// deprecatedCommandFiles are the environment files replacing the deprecated
+// workflow commands.
+var deprecatedCommandFiles = map[string]string{
+	"set-output": "GITHUB_OUTPUT",
+	"save-state": "GITHUB_STATE",
+	"set-env":    "GITHUB_ENV",
+	"add-path":   "GITHUB_PATH",
+}
+
+// RuleDeprecatedCommand reports workflow commands in run scripts which
+// GitHub disabled or deprecated in favor of environment files.
+type RuleDeprecatedCommand struct {
+	RuleBase
+	src []string
+}
+
+// NewRuleDeprecatedCommand creates the rule for the action metadata with
+// source src.
+func NewRuleDeprecatedCommand(src []byte) *RuleDeprecatedCommand {
+	return &RuleDeprecatedCommand{
+		RuleBase: RuleBase{
+			name: "deprecated-command",
+			desc: "Checks run scripts do not use deprecated workflow commands",
+		},
+		src: sourceLines(src),
+	}
+}
+
+func (rule *RuleDeprecatedCommand) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecRun)
+	if !ok || e.Run == nil {
+		return nil
+	}
+
+	pwsh := e.Shell != nil && (e.Shell.Value == "pwsh" || e.Shell.Value == "powershell")
+	posAt := stringPos(rule.src, e.Run)
+	for _, m := range deprecatedCommandRe.FindAllStringSubmatchIndex(e.Run.Value, -1) {
+		cmd := e.Run.Value[m[2]:m[3]]
+		file := deprecatedCommandFiles[cmd]
+
+		line := "{name}={value}"
+		if m[4] >= 0 {
+			line = e.Run.Value[m[4]:m[5]] + "={value}"
+		}
+		if cmd == "add-path" {
+			line = "{path}"
+		}
+		replacement := fmt.Sprintf(`echo "%s" >> "$%s"`, line, file)
+		if pwsh {
+			replacement = fmt.Sprintf(`"%s" >> $env:%s`, line, file)
+		}
+
+		pos := posAt(m[0])
+		rule.Errorf(&pos, "workflow command %q is deprecated. write to the $%s file instead, like '%s'. see https://docs.github.com/en/actions/reference/workflows-and-actions/workflow-commands#environment-files", "::"+cmd, file, replacement)
+	}
+	return nil
+}
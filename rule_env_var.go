@@ -0,0 +1,87 @@
+package compositeactionlint
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// envVarNameRe is the pattern of environment variable names which shells
+// on all runners can access.
+var envVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedEnvVarPrefixes are prefixes of the environment variables the runner
+// sets. Assignments to them are ignored.
+// https://docs.github.com/en/actions/reference/workflows-and-actions/variables#default-environment-variables
+var reservedEnvVarPrefixes = []string{"GITHUB_", "RUNNER_"}
+
+// RuleEnvVar checks names of the environment variables of steps.
+type RuleEnvVar struct {
+	RuleBase
+}
+
+func NewRuleEnvVar() *RuleEnvVar {
+	return &RuleEnvVar{
+		RuleBase: RuleBase{
+			name: "env-var",
+			desc: "Checks names of environment variables of steps",
+		},
+	}
+}
+
+func (rule *RuleEnvVar) VisitStep(n *Step) error {
+	vars := make([]*al.EnvVar, 0, len(n.Env))
+	for _, v := range n.Env {
+		vars = append(vars, v)
+	}
+	slices.SortFunc(vars, func(a, b *al.EnvVar) int {
+		return cmp.Or(cmp.Compare(a.Name.Pos.Line, b.Name.Pos.Line), cmp.Compare(a.Name.Pos.Col, b.Name.Pos.Col))
+	})
+
+	seen := map[string]*String{}
+	for _, v := range vars {
+		name := v.Name
+		if name.ContainsExpression() {
+			continue
+		}
+		if !envVarNameRe.MatchString(name.Value) {
+			rule.Errorf(name.Pos, "invalid environment variable name %q. names must start with a letter or \"_\" and contain only alphanumeric characters and \"_\"", name.Value)
+			continue
+		}
+
+		upper := strings.ToUpper(name.Value)
+		if prev, ok := seen[upper]; ok {
+			rule.Errorf(name.Pos, "environment variable %q differs only in case from %q at %s. they are the same variable on Windows runners", name.Value, prev.Value, prev.Pos.String())
+		} else {
+			seen[upper] = name
+		}
+
+		// Names are case-sensitive on Linux and macOS, so only the names the
+		// runner sets are reported. Other cases are reported above on Windows
+		if name.Value == "CI" {
+			// GitHub documents CI can currently be overwritten
+			rule.Errorf(name.Pos, "environment variable %q is set by the runner. overriding it is not guaranteed to keep working", name.Value)
+		} else if isReservedEnvVar(name.Value) {
+			rule.Errorf(name.Pos, "environment variable %q is reserved. variables starting with \"GITHUB_\" or \"RUNNER_\" are set by the runner and cannot be overridden", name.Value)
+		}
+	}
+	return nil
+}
+
+// isReservedEnvVar reports whether name is a variable the
+// runner sets which cannot be overridden.
+func isReservedEnvVar(name string) bool {
+	// GITHUB_TOKEN is not set by the runner, but commonly passed to tools
+	if name == "GITHUB_TOKEN" {
+		return false
+	}
+	for _, p := range reservedEnvVarPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,15 @@ package compositeactionlint
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	al "github.com/rhysd/actionlint"
 )
 
+var undefinedPropertyRe = regexp.MustCompile(`^property "([^"]+)" is not defined in object type `)
+
 type typedExpr struct {
 	ty  ExprType
 	pos Pos
@@ -14,17 +18,26 @@ type typedExpr struct {
 
 type RuleExpression struct {
 	RuleBase
+	src      []string
 	metadata *ActionMetadata
 	inputsTy *ObjectType
 	stepsTy  *ObjectType
+	// stepIDs are the IDs of all steps by their lower case, known up front
+	// to report references to steps which have not run yet
+	stepIDs map[string]*String
+	// current is the ID of the step being visited
+	current *String
 }
 
-func NewRuleExpression() *RuleExpression {
+// NewRuleExpression creates a new RuleExpression. src is the source of the
+// action metadata file, used to locate expressions inside block scalars.
+func NewRuleExpression(src []byte) *RuleExpression {
 	return &RuleExpression{
 		RuleBase: RuleBase{
 			name: "expression",
 			desc: "Syntax and semantics checks for expressions embedded with ${{ }} syntax",
 		},
+		src: sourceLines(src),
 	}
 }
 
@@ -51,6 +64,17 @@ func (rule *RuleExpression) VisitActionMetadataPre(node *ActionMetadata) error {
 
 	rule.metadata = node
 	rule.stepsTy = al.NewEmptyStrictObjectType()
+	rule.stepIDs = map[string]*String{}
+	if node.Runs != nil {
+		for _, s := range node.Runs.Steps {
+			if s.ID == nil || s.ID.ContainsExpression() {
+				continue
+			}
+			if _, ok := rule.stepIDs[strings.ToLower(s.ID.Value)]; !ok {
+				rule.stepIDs[strings.ToLower(s.ID.Value)] = s.ID
+			}
+		}
+	}
 	return nil
 }
 
@@ -69,17 +93,21 @@ func (rule *RuleExpression) VisitActionMetadataPost(n *ActionMetadata) error {
 }
 
 func (rule *RuleExpression) VisitStep(n *Step) error {
+	rule.current = nil
+	if n.ID != nil {
+		rule.current = rule.stepIDs[strings.ToLower(n.ID.Value)]
+	}
+
 	rule.checkString(n.Name, "runs.steps.name")
 	rule.checkIfCondition(n.If, "runs.steps.if")
 
+	// Expressions in shell and uses are reported by the literal rule
 	var spec *String
 	switch e := n.Exec.(type) {
 	case *al.ExecRun:
 		rule.checkScriptString(e.Run, "runs.steps.run")
-		rule.checkString(e.Shell, "")
 		rule.checkString(e.WorkingDirectory, "runs.steps.working-directory")
 	case *al.ExecAction:
-		rule.checkString(e.Uses, "")
 		for n, i := range e.Inputs {
 			if e.Uses != nil && strings.HasPrefix(e.Uses.Value, "actions/github-script@") && n == "script" {
 				rule.checkScriptString(i.Value, "runs.steps.with")
@@ -94,8 +122,8 @@ func (rule *RuleExpression) VisitStep(n *Step) error {
 	rule.checkBool(n.ContinueOnError, "runs.steps.continue-on-error")
 
 	if n.ID != nil {
+		// Expressions in IDs are reported by the literal rule
 		if n.ID.ContainsExpression() {
-			rule.checkString(n.ID, "")
 			rule.stepsTy.Loose()
 		}
 		// Step ID is case insensitive
@@ -215,7 +243,7 @@ func (rule *RuleExpression) checkString(str *String, workflowKey string) []typed
 		return nil
 	}
 
-	ts, ok := rule.checkExprsIn(str.Value, str.Pos, str.Quoted, false, workflowKey)
+	ts, ok := rule.checkExprsIn(str, false, workflowKey)
 	if !ok {
 		return nil
 	}
@@ -229,7 +257,7 @@ func (rule *RuleExpression) checkScriptString(str *String, workflowKey string) {
 		return
 	}
 
-	ts, ok := rule.checkExprsIn(str.Value, str.Pos, str.Quoted, true, workflowKey)
+	ts, ok := rule.checkExprsIn(str, true, workflowKey)
 	if !ok {
 		return
 	}
@@ -255,13 +283,10 @@ func (rule *RuleExpression) checkBool(b *Bool, workflowKey string) {
 	}
 }
 
-func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrusted bool, workflowKey string) ([]typedExpr, bool) {
-	// TODO: Line number is not correct when the string contains newlines.
+func (rule *RuleExpression) checkExprsIn(str *String, checkUntrusted bool, workflowKey string) ([]typedExpr, bool) {
+	s := str.Value
+	posAt := stringPos(rule.src, str)
 
-	line, col := pos.Line, pos.Col
-	if quoted {
-		col++ // when the string is quoted like 'foo' or "foo", column should be incremented
-	}
 	offset := 0
 	ts := []typedExpr{}
 	for {
@@ -273,16 +298,16 @@ func (rule *RuleExpression) checkExprsIn(s string, pos *Pos, quoted, checkUntrus
 		start := idx + 3 // 3 means removing "${{"
 		s = s[start:]
 		offset += start
-		col := col + offset
+		pos := posAt(offset)
 
-		ty, offsetAfter, ok := rule.checkSemantics(s, line, col, checkUntrusted, workflowKey)
+		ty, offsetAfter, ok := rule.checkSemantics(s, pos.Line, pos.Col, checkUntrusted, workflowKey)
 		if !ok {
 			return nil, false
 		}
 		if ty == nil || offsetAfter == 0 {
 			return nil, true
 		}
-		ts = append(ts, typedExpr{ty, Pos{Line: line, Col: col - 3}})
+		ts = append(ts, typedExpr{ty, posAt(offset - 3)})
 
 		s = s[offsetAfter:]
 		offset += offsetAfter
@@ -296,6 +321,79 @@ func (rule *RuleExpression) exprError(err *ExprError, lineBase, colBase int) {
 	rule.Error(pos, err.Message)
 }
 
+// propertySuggestion returns a note naming the closest property of the
+// receiver when err reports access to an undefined property in expr, and ""
+// otherwise.
+func (rule *RuleExpression) propertySuggestion(expr ExprNode, err *ExprError) string {
+	ss := undefinedPropertyRe.FindStringSubmatch(err.Message)
+	if ss == nil {
+		return ""
+	}
+
+	var recv *ObjectType
+	al.VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering || recv != nil {
+			return
+		}
+		// The receiver and all its accesses share the position of the
+		// receiver's first token, so the property tells them apart
+		if t := n.Token(); t.Line != err.Line || t.Column != err.Column {
+			return
+		}
+		switch n := n.(type) {
+		case *al.ObjectDerefNode:
+			if n.Property == ss[1] {
+				recv = rule.objectTypeOf(n.Receiver)
+			}
+		case *al.IndexAccessNode:
+			if idx, ok := n.Index.(*al.StringNode); ok && idx.Value == ss[1] {
+				recv = rule.objectTypeOf(n.Operand)
+			}
+		}
+	})
+	if recv == nil {
+		return ""
+	}
+
+	props := make([]string, 0, len(recv.Props))
+	for p := range recv.Props {
+		props = append(props, p)
+	}
+	slices.Sort(props)
+	if c := closestMatch(ss[1], props); c != "" {
+		return fmt.Sprintf(". did you mean %q?", c)
+	}
+	return ""
+}
+
+// objectTypeOf returns the type of n when it is an object built from the
+// inputs and steps of this action or a builtin context, and nil otherwise.
+func (rule *RuleExpression) objectTypeOf(n ExprNode) *ObjectType {
+	var ty ExprType
+	switch n := n.(type) {
+	case *al.VariableNode:
+		switch n.Name {
+		case "inputs":
+			return rule.inputsTy
+		case "steps":
+			return rule.stepsTy
+		}
+		ty = al.BuiltinGlobalVariableTypes[n.Name]
+	case *al.ObjectDerefNode:
+		if o := rule.objectTypeOf(n.Receiver); o != nil {
+			ty = o.Props[n.Property]
+		}
+	case *al.IndexAccessNode:
+		if idx, ok := n.Index.(*al.StringNode); ok {
+			if o := rule.objectTypeOf(n.Operand); o != nil {
+				ty = o.Props[strings.ToLower(idx.Value)]
+			}
+		}
+	}
+	o, _ := ty.(*ObjectType)
+	return o
+}
+
 func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col int, checkUntrusted bool, workflowKey string) (ExprType, bool) {
 	var v []string
 	//if rule.config != nil {
@@ -318,14 +416,63 @@ func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col in
 		c.SetSpecialFunctionAvailability(sp)
 	}
 
+	unrun := rule.checkUnrunStepRefs(expr, line, col)
 	ty, errs := c.Check(expr)
 	for _, err := range errs {
-		rule.exprError(err, line, col)
+		if unrun[[2]int{err.Line, err.Column}] {
+			continue // Reported with the position of the step
+		}
+		pos := convertExprLineColToPos(err.Line, err.Column, line, col)
+		rule.Error(pos, err.Message+rule.propertySuggestion(expr, err))
 	}
 
 	return ty, len(errs) == 0
 }
 
+// checkUnrunStepRefs reports references in expr to the current step or to
+// steps defined after it, which have not run yet. It returns the positions
+// of the `steps` context of those references in expr, where the semantics
+// checker reports them as undefined properties.
+func (rule *RuleExpression) checkUnrunStepRefs(expr ExprNode, line, col int) map[[2]int]bool {
+	unrun := map[[2]int]bool{}
+	if rule.stepsTy == nil || rule.stepsTy.IsLoose() {
+		return unrun
+	}
+	al.VisitExprNode(expr, func(n, parent ExprNode, entering bool) {
+		v, ok := n.(*al.VariableNode)
+		if !entering || !ok || !strings.EqualFold(v.Name, "steps") {
+			return
+		}
+		var prop string
+		switch p := parent.(type) {
+		case *al.ObjectDerefNode:
+			prop = p.Property
+		case *al.IndexAccessNode:
+			if idx, ok := p.Index.(*al.StringNode); ok && p.Operand == n {
+				prop = idx.Value
+			}
+		}
+		key := strings.ToLower(prop)
+		id, ok := rule.stepIDs[key]
+		if !ok {
+			return
+		}
+		if _, ok := rule.stepsTy.Props[key]; ok {
+			return
+		}
+
+		t := v.Token()
+		unrun[[2]int{t.Line, t.Column}] = true
+		pos := convertExprLineColToPos(t.Line, t.Column, line, col)
+		if rule.current == id {
+			rule.Errorf(pos, "step %q refers to itself. outputs, outcome and conclusion of a step are only available to the steps after it", prop)
+		} else {
+			rule.Errorf(pos, "step %q is referenced before it runs. it is defined later at %s", prop, id.Pos.String())
+		}
+	})
+	return unrun
+}
+
 func (rule *RuleExpression) checkSemantics(src string, line, col int, checkUntrusted bool, workflowKey string) (ExprType, int, bool) {
 	l := al.NewExprLexer(src)
 	p := al.NewExprParser()
@@ -344,7 +491,7 @@ func (rule *RuleExpression) checkOneExpression(s *String, what, workflowKey stri
 		return nil
 	}
 
-	ts, ok := rule.checkExprsIn(s.Value, s.Pos, s.Quoted, false, workflowKey)
+	ts, ok := rule.checkExprsIn(s, false, workflowKey)
 	if !ok {
 		return nil
 	}
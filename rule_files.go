@@ -0,0 +1,144 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// actionPathRefRe matches paths under the action directory in scripts, like
+// `${{ github.action_path }}/scripts/build.sh` or `$GITHUB_ACTION_PATH/x.sh`.
+// The path is the first group.
+var actionPathRefRe = regexp.MustCompile(`(?:\$\{\{\s*github\.action_path\s*\}\}|\$\{GITHUB_ACTION_PATH\}|\$GITHUB_ACTION_PATH)/([^\s;&|()<>'"` + "`" + `$*?\[{]+)`)
+
+// RuleFiles checks files of the action referenced from its metadata exist:
+// scripts run through github.action_path, the Dockerfile of docker actions
+// and the entrypoints of JavaScript actions.
+type RuleFiles struct {
+	RuleBase
+	src []string
+	// dir is the directory of the action.
+	dir string
+}
+
+// NewRuleFiles creates the rule for the action metadata file at path with
+// source src.
+func NewRuleFiles(path string, src []byte) *RuleFiles {
+	rule := &RuleFiles{
+		RuleBase: RuleBase{
+			name: "files",
+			desc: "Checks files referenced by the action exist in its directory",
+		},
+		src: sourceLines(src),
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		rule.dir = filepath.Dir(abs)
+	}
+	return rule
+}
+
+func (rule *RuleFiles) VisitActionMetadataPre(n *ActionMetadata) error {
+	if n.Runs == nil || rule.dir == "" {
+		return nil
+	}
+	for _, f := range []struct {
+		key string
+		s   *String
+	}{
+		{"main", n.Runs.Main},
+		{"pre", n.Runs.Pre},
+		{"post", n.Runs.Post},
+	} {
+		if f.s != nil && f.s.Value != "" && !f.s.ContainsExpression() {
+			rule.checkExists(f.s.Pos, f.s.Value, "\""+f.key+"\"")
+		}
+	}
+
+	// Anything but a docker:// image is a Dockerfile in the action
+	if img := n.Runs.Image; img != nil && img.Value != "" && !img.ContainsExpression() && !strings.HasPrefix(img.Value, "docker://") {
+		rule.checkExists(img.Pos, img.Value, "\"image\"")
+	}
+	return nil
+}
+
+func (rule *RuleFiles) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecRun)
+	if !ok || e.Run == nil || rule.dir == "" {
+		return nil
+	}
+
+	script := e.Run.Value
+	posAt := stringPos(rule.src, e.Run)
+	for _, m := range actionPathRefRe.FindAllStringSubmatchIndex(script, -1) {
+		// Paths built from variables or globs cannot be resolved
+		if m[1] < len(script) && strings.ContainsRune("$*?[{", rune(script[m[1]])) {
+			continue
+		}
+		pos := posAt(m[0])
+		p := script[m[2]:m[3]]
+		info := rule.checkExists(&pos, p, "the script")
+		if info == nil || info.IsDir() || info.Mode()&0o111 != 0 || !executedDirectly(script, m[0]) {
+			continue
+		}
+		rule.Errorf(&pos, "%q is executed directly but it is not executable. commit it with the executable bit set (chmod +x) or run it with its interpreter like \"bash %s\"", p, script[m[0]:m[1]])
+	}
+	return nil
+}
+
+// checkExists reports p when it does not exist relative to the action's
+// directory, with the file at the closest path as suggestion. what names
+// where p is referenced from. Paths leaving the directory are not checked.
+func (rule *RuleFiles) checkExists(pos *Pos, p, what string) os.FileInfo {
+	rel := filepath.Clean(filepath.FromSlash(p))
+	if s := filepath.ToSlash(rel); s == ".." || strings.HasPrefix(s, "../") || filepath.IsAbs(rel) {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(rule.dir, rel))
+	if err == nil {
+		return info
+	}
+
+	m := fmt.Sprintf("file %q referenced by %s does not exist in the directory of this action", p, what)
+	if !os.IsNotExist(err) {
+		rule.Error(pos, m+": "+err.Error())
+		return nil
+	}
+	names := []string{}
+	parent := filepath.Dir(rel)
+	if entries, err := os.ReadDir(filepath.Join(rule.dir, parent)); err == nil {
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+	}
+	if s := closestMatch(filepath.Base(rel), names); s != "" {
+		m += fmt.Sprintf(". did you mean %q?", filepath.ToSlash(filepath.Join(parent, s)))
+	}
+	rule.Error(pos, m)
+	return nil
+}
+
+// executedDirectly reports whether the word at offset of script is in the
+// position of a command, rather than an argument of an interpreter.
+func executedDirectly(script string, offset int) bool {
+	before := script[:offset]
+	if i := strings.LastIndexByte(before, '\n'); i >= 0 {
+		before = before[i+1:]
+	}
+	before = strings.TrimRight(before, " \t\"'")
+	if before == "" {
+		return true
+	}
+	if strings.ContainsRune(";&|(", rune(before[len(before)-1])) {
+		return true
+	}
+	for _, kw := range []string{"then", "do", "else", "exec", "time"} {
+		if before == kw || strings.HasSuffix(before, " "+kw) || strings.HasSuffix(before, "\t"+kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package compositeactionlint
+
+import (
+	"cmp"
+	"slices"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// RuleLiteral reports expressions in values GitHub uses literally without
+// evaluating them.
+type RuleLiteral struct {
+	RuleBase
+}
+
+func NewRuleLiteral() *RuleLiteral {
+	return &RuleLiteral{
+		RuleBase: RuleBase{
+			name: "literal",
+			desc: "Checks expressions are not used in values GitHub does not evaluate",
+		},
+	}
+}
+
+func (rule *RuleLiteral) VisitActionMetadataPre(n *ActionMetadata) error {
+	ids := []*String{}
+	for _, i := range n.Inputs {
+		ids = append(ids, i.ID)
+	}
+	for _, o := range n.Outputs {
+		ids = append(ids, o.ID)
+	}
+	slices.SortFunc(ids, func(a, b *String) int {
+		return cmp.Or(cmp.Compare(a.Pos.Line, b.Pos.Line), cmp.Compare(a.Pos.Col, b.Pos.Col))
+	})
+	for _, id := range ids {
+		rule.check(id, "IDs of inputs and outputs")
+	}
+
+	if n.Runs != nil {
+		rule.check(n.Runs.Using, "\"runs.using\"")
+	}
+	return nil
+}
+
+func (rule *RuleLiteral) VisitStep(n *Step) error {
+	rule.check(n.ID, "step IDs")
+	switch e := n.Exec.(type) {
+	case *al.ExecRun:
+		rule.check(e.Shell, "\"shell\" of steps")
+	case *al.ExecAction:
+		rule.check(e.Uses, "\"uses\" of steps")
+	}
+	return nil
+}
+
+// check reports s when it contains an expression. where describes the values
+// like s.
+func (rule *RuleLiteral) check(s *String, where string) {
+	if s == nil || !s.ContainsExpression() {
+		return
+	}
+	rule.Errorf(s.Pos, "%q contains an expression but GitHub does not evaluate expressions in %s. the value is used literally", s.Value, where)
+}
@@ -0,0 +1,52 @@
+package compositeactionlint
+
+import (
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// RuleLocalUses reports `uses: ./...` in actions which look like they are
+// published for use from other repositories. Inside a composite action such
+// paths are resolved against $GITHUB_WORKSPACE, the repository of the
+// workflow calling the action, rather than the repository of the action.
+type RuleLocalUses struct {
+	RuleBase
+	// published is whether the linted action looks like it is used from
+	// other repositories.
+	published bool
+	// repo is "{owner}/{repo}" of the action's repository, if known.
+	repo string
+}
+
+// NewRuleLocalUses creates the rule for the action metadata file at path.
+func NewRuleLocalUses(path string) *RuleLocalUses {
+	return &RuleLocalUses{
+		RuleBase: RuleBase{
+			name: "local-uses",
+			desc: "Checks local actions are not used from actions published for other repositories",
+		},
+		published: isPublishedAction(path),
+		repo:      repositoryName(workspaceRoot(path)),
+	}
+}
+
+func (rule *RuleLocalUses) VisitStep(n *Step) error {
+	if !rule.published {
+		return nil
+	}
+	e, ok := n.Exec.(*al.ExecAction)
+	if !ok || e.Uses == nil || !strings.HasPrefix(e.Uses.Value, "./") {
+		return nil
+	}
+
+	repo := rule.repo
+	if repo == "" {
+		repo = "{owner}/{repo}"
+	}
+	if p := strings.Trim(e.Uses.Value[len("./"):], "/"); p != "" {
+		repo += "/" + p
+	}
+	rule.Errorf(e.Uses.Pos, "local action %q is resolved against the workspace of the workflow using this action, not against the repository of this action, so it breaks when this action is used from another repository. use %q instead", e.Uses.Value, repo+"@{ref}")
+	return nil
+}
@@ -0,0 +1,87 @@
+package compositeactionlint
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+var (
+	fullSHARe    = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	shortSHARe   = regexp.MustCompile(`^[0-9a-f]{7,39}$`)
+	versionTagRe = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.-]+)?$`)
+)
+
+// RulePinning enforces the pinning policy of the configuration on the refs of
+// actions used by composite steps.
+type RulePinning struct {
+	RuleBase
+	config *PinningConfig
+}
+
+func NewRulePinning(config *PinningConfig) *RulePinning {
+	return &RulePinning{
+		RuleBase: RuleBase{
+			name: "pinning",
+			desc: "Checks actions used by composite steps are pinned as the configuration requires",
+		},
+		config: config,
+	}
+}
+
+func (rule *RulePinning) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecAction)
+	if !ok || e.Uses == nil || e.Uses.ContainsExpression() {
+		return nil
+	}
+
+	// Invalid references are reported by the uses rule
+	ref, err := parseActionRef(e.Uses.Value)
+	if err != nil {
+		return nil
+	}
+
+	switch ref.kind {
+	case actionRefRepo:
+		rule.checkRepoAction(e.Uses, ref)
+	case actionRefDocker:
+		if rule.config.RequireSHA && ref.digest == "" {
+			rule.Errorf(e.Uses.Pos, "docker image %q must be pinned by digest like \"docker://%s@sha256:{digest}\"", ref.image, ref.image)
+		}
+	}
+	return nil
+}
+
+func (rule *RulePinning) checkRepoAction(uses *String, ref *actionRef) {
+	// Owner and repository names are case insensitive on GitHub
+	name := strings.ToLower(ref.owner + "/" + ref.repo)
+	action := uses.Value[:len(uses.Value)-len(ref.ref)-1]
+
+	if fullSHARe.MatchString(ref.ref) {
+		return
+	}
+	if shortSHARe.MatchString(ref.ref) && !versionTagRe.MatchString(ref.ref) {
+		rule.Errorf(uses.Pos, "commit SHA %q of action %q is abbreviated. use the full 40 character SHA", ref.ref, action)
+		return
+	}
+
+	isTag := versionTagRe.MatchString(ref.ref)
+	if !isTag && rule.config.ForbidBranches {
+		rule.Errorf(uses.Pos, "action %q is used at %q which looks like a branch. branches move, pin it to a full commit SHA like \"%s@{sha} # {tag}\"", action, ref.ref, action)
+		return
+	}
+
+	if !rule.config.RequireSHA {
+		return
+	}
+	if isTag {
+		for _, pat := range rule.config.AllowTags {
+			if ok, _ := path.Match(strings.ToLower(pat), name); ok {
+				return
+			}
+		}
+	}
+	rule.Errorf(uses.Pos, "action %q must be pinned to a full commit SHA instead of %q. note the version in a comment like \"%s@{sha} # %s\"", action, ref.ref, action, ref.ref)
+}
@@ -0,0 +1,110 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// RulePolicy checks actions used by composite steps against the allow and
+// deny lists of the configuration.
+type RulePolicy struct {
+	RuleBase
+	config *PolicyConfig
+}
+
+func NewRulePolicy(config *PolicyConfig) *RulePolicy {
+	return &RulePolicy{
+		RuleBase: RuleBase{
+			name: "policy",
+			desc: "Checks actions used by composite steps are allowed by the configured policy",
+		},
+		config: config,
+	}
+}
+
+func (rule *RulePolicy) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecAction)
+	if !ok || e.Uses == nil || e.Uses.ContainsExpression() {
+		return nil
+	}
+	spec := e.Uses.Value
+
+	// Local actions are part of the repository itself
+	if strings.HasPrefix(spec, "./") {
+		return nil
+	}
+
+	for _, d := range rule.config.Deny {
+		if ok, _ := matchPolicyPattern(d.Pattern, spec); ok {
+			m := fmt.Sprintf("action %q is denied by entry %q of policy.deny", spec, d.Pattern)
+			if d.Reason != "" {
+				m += ": " + d.Reason
+			}
+			rule.Error(e.Uses.Pos, m)
+			return nil
+		}
+	}
+
+	if len(rule.config.Allow) == 0 {
+		return nil
+	}
+	pats := make([]string, 0, len(rule.config.Allow))
+	for _, a := range rule.config.Allow {
+		if ok, _ := matchPolicyPattern(a.Pattern, spec); ok {
+			return nil
+		}
+		pats = append(pats, a.Pattern)
+	}
+	rule.Errorf(e.Uses.Pos, "action %q is not allowed since it matches no entry of policy.allow [%s]", spec, strings.Join(pats, ","))
+	return nil
+}
+
+// matchPolicyPattern reports whether spec, the value of `uses:`, matches pat.
+// Owner, repository, path and ref of pat are matched separately. Omitting the
+// path or the ref from pat matches any path or ref. Owner and repository are
+// matched regardless of case.
+func matchPolicyPattern(pat, spec string) (bool, error) {
+	if strings.HasPrefix(pat, "docker://") {
+		return path.Match(pat, spec)
+	}
+
+	patRepo, patRef, hasRef := strings.Cut(pat, "@")
+	patSegs := strings.SplitN(patRepo, "/", 3)
+	if len(patSegs) < 2 {
+		return false, fmt.Errorf("pattern should look like \"{owner}/{repo}[/{path}][@{ref}]\"")
+	}
+
+	// Validate the pattern even when spec cannot match
+	for _, p := range append(patSegs, patRef) {
+		if _, err := path.Match(p, ""); err != nil {
+			return false, err
+		}
+	}
+
+	ref, err := parseActionRef(spec)
+	if err != nil || ref.kind != actionRefRepo {
+		return false, nil
+	}
+
+	// Owner and repository names are case insensitive on GitHub
+	if ok, _ := path.Match(strings.ToLower(patSegs[0]), strings.ToLower(ref.owner)); !ok {
+		return false, nil
+	}
+	if ok, _ := path.Match(strings.ToLower(patSegs[1]), strings.ToLower(ref.repo)); !ok {
+		return false, nil
+	}
+	if len(patSegs) == 3 {
+		if ok, _ := path.Match(patSegs[2], ref.path); !ok {
+			return false, nil
+		}
+	}
+	if hasRef {
+		if ok, _ := path.Match(patRef, ref.ref); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,82 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// shellRunnerOS lists the runner operating systems each built-in shell is
+// available on.
+// https://docs.github.com/en/actions/reference/workflows-and-actions/workflow-syntax#jobsjob_idstepsshell
+var shellRunnerOS = map[string][]string{
+	"bash":       {"linux", "macos", "windows"},
+	"sh":         {"linux", "macos"},
+	"pwsh":       {"linux", "macos", "windows"},
+	"powershell": {"windows"},
+	"python":     {"linux", "macos", "windows"},
+	"cmd":        {"windows"},
+}
+
+// RunnerOSNames are the runner operating systems the runner-os
+// configuration accepts.
+var RunnerOSNames = []string{"linux", "macos", "windows"}
+
+// RuleShell checks `shell:` of run steps is a built-in shell or a custom
+// shell command template.
+type RuleShell struct {
+	RuleBase
+	// runnerOS is the configured operating systems the action runs on. Not
+	// checked when empty.
+	runnerOS []string
+}
+
+func NewRuleShell(runnerOS []string) *RuleShell {
+	return &RuleShell{
+		RuleBase: RuleBase{
+			name: "shell",
+			desc: "Checks shells of run steps are available",
+		},
+		runnerOS: runnerOS,
+	}
+}
+
+func (rule *RuleShell) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecRun)
+	if !ok || e.Shell == nil || e.Shell.Value == "" || e.Shell.ContainsExpression() {
+		return nil
+	}
+
+	sh := e.Shell.Value
+	if strings.Contains(sh, "{0}") {
+		return nil // Custom shell
+	}
+	if strings.ContainsAny(sh, " \t") {
+		rule.Errorf(e.Shell.Pos, "custom shell %q must contain \"{0}\" as the placeholder of the path to the script, like \"%s {0}\"", sh, sh)
+		return nil
+	}
+
+	oses, ok := shellRunnerOS[sh]
+	if !ok {
+		names := make([]string, 0, len(shellRunnerOS))
+		for name := range shellRunnerOS {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		m := fmt.Sprintf("unknown shell %q. available shells are [%s], or a custom command containing \"{0}\" like \"%s {0}\"", sh, strings.Join(names, ","), sh)
+		if s := closestMatch(sh, names); s != "" {
+			m += fmt.Sprintf(". did you mean %q?", s)
+		}
+		rule.Error(e.Shell.Pos, m)
+		return nil
+	}
+
+	for _, os := range rule.runnerOS {
+		if !slices.Contains(oses, os) {
+			rule.Errorf(e.Shell.Pos, "shell %q is not available on %s runners, which the configuration says this action runs on. it is only available on [%s]", sh, os, strings.Join(oses, ","))
+		}
+	}
+	return nil
+}
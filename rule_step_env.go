@@ -0,0 +1,78 @@
+package compositeactionlint
+
+import (
+	"regexp"
+	"strings"
+
+	al "github.com/rhysd/actionlint"
+)
+
+// envFileNameRe matches the names of variables in lines written to
+// $GITHUB_ENV, like `NAME=value` or `NAME<<EOF`.
+var envFileNameRe = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)(?:=|<<)`)
+
+// RuleStepEnv reports `env.X` in the `if:` condition of a step when X is
+// defined by the `env:` of the same step. The env of a step is set only
+// when the step runs, after its condition is evaluated. Variables which
+// previous steps write to $GITHUB_ENV are not reported.
+type RuleStepEnv struct {
+	RuleBase
+	src []string
+	// exported are the lower case names of the variables previous steps
+	// write to $GITHUB_ENV
+	exported map[string]bool
+}
+
+// NewRuleStepEnv creates the rule for the action metadata with source src.
+func NewRuleStepEnv(src []byte) *RuleStepEnv {
+	return &RuleStepEnv{
+		RuleBase: RuleBase{
+			name: "step-env",
+			desc: "Checks conditions of steps do not use the env of the same step",
+		},
+		src: sourceLines(src),
+	}
+}
+
+func (rule *RuleStepEnv) VisitActionMetadataPre(n *ActionMetadata) error {
+	rule.exported = map[string]bool{}
+	return nil
+}
+
+func (rule *RuleStepEnv) VisitStep(n *Step) error {
+	defer rule.collectExported(n)
+	if n.If == nil || len(n.Env) == 0 {
+		return nil
+	}
+
+	defined := map[string]*String{}
+	for _, v := range n.Env {
+		defined[strings.ToLower(v.Name.Value)] = v.Name
+	}
+
+	cond := conditionString(n.If)
+	posAt := stringPos(rule.src, cond)
+	visitContextRefs(cond, "env", func(name string, offset int) {
+		v, ok := defined[name]
+		if !ok || rule.exported[name] {
+			return
+		}
+		pos := posAt(offset)
+		rule.Errorf(&pos, "\"env.%s\" in the condition of this step does not refer to %q defined in the env of this step at %s, since the env of a step is not available to its \"if\". define the variable in the calling workflow or in a previous step with $GITHUB_ENV", v.Value, v.Value, v.Pos.String())
+	})
+	return nil
+}
+
+// collectExported remembers the names of variables the run script of n
+// writes to $GITHUB_ENV. Any assignment in a script using $GITHUB_ENV is
+// taken as exported, since the lines may be written in another place than
+// they are built.
+func (rule *RuleStepEnv) collectExported(n *Step) {
+	e, ok := n.Exec.(*al.ExecRun)
+	if !ok || e.Run == nil || !strings.Contains(e.Run.Value, "GITHUB_ENV") {
+		return
+	}
+	for _, m := range envFileNameRe.FindAllStringSubmatch(e.Run.Value, -1) {
+		rule.exported[strings.ToLower(m[1])] = true
+	}
+}
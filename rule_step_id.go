@@ -0,0 +1,103 @@
+package compositeactionlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// stepIDRe is the pattern of step IDs GitHub accepts.
+// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runsstepsid
+var stepIDRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// RuleStepID checks step IDs are valid, unique and referenced.
+type RuleStepID struct {
+	RuleBase
+	// ids are the IDs of the steps visited so far by their lower case
+	ids map[string]*String
+	// order is the lower case valid IDs in the order of the steps
+	order []string
+	// refs are the lower case IDs referenced through `steps.<id>`
+	refs map[string]bool
+	// allRefs is set when `steps` is used as a whole, like in
+	// toJSON(steps), so that any step may be referenced
+	allRefs bool
+}
+
+func NewRuleStepID() *RuleStepID {
+	return &RuleStepID{
+		RuleBase: RuleBase{
+			name: "step-id",
+			desc: "Checks step IDs are valid, unique and referenced",
+		},
+	}
+}
+
+func (rule *RuleStepID) VisitActionMetadataPre(n *ActionMetadata) error {
+	rule.ids = map[string]*String{}
+	rule.order = nil
+	rule.refs = map[string]bool{}
+	rule.allRefs = false
+	return nil
+}
+
+func (rule *RuleStepID) VisitStep(n *Step) error {
+	for _, s := range compositeStepStrings(n) {
+		rule.collectRefs(s)
+	}
+
+	if n.ID == nil || n.ID.ContainsExpression() {
+		return nil
+	}
+
+	id := n.ID.Value
+	valid := false
+	if strings.HasPrefix(id, "__") {
+		rule.Errorf(n.ID.Pos, "step ID %q must not start with \"__\", which is reserved for the IDs GitHub generates for steps without one", id)
+	} else if !stepIDRe.MatchString(id) {
+		rule.Errorf(n.ID.Pos, "invalid step ID %q. step IDs must start with a letter or \"_\" and contain only alphanumeric characters, \"-\" or \"_\"", id)
+	} else {
+		valid = true
+	}
+
+	// Step ID is case insensitive
+	key := strings.ToLower(id)
+	if prev, ok := rule.ids[key]; ok {
+		rule.Errorf(n.ID.Pos, "step ID %q duplicates. previously defined at %s. step ID must be unique within the action. note that step ID is case insensitive", id, prev.Pos.String())
+		return nil
+	}
+	rule.ids[key] = n.ID
+	// Invalid IDs are only reported once
+	if valid {
+		rule.order = append(rule.order, key)
+	}
+	return nil
+}
+
+func (rule *RuleStepID) VisitActionMetadataPost(n *ActionMetadata) error {
+	for _, o := range n.Outputs {
+		rule.collectRefs(o.Value)
+	}
+	if rule.allRefs {
+		return nil
+	}
+	for _, key := range rule.order {
+		if rule.refs[key] {
+			continue
+		}
+		id := rule.ids[key]
+		rule.Errorf(id.Pos, "step ID %q is not referenced by any expression. remove the ID or use the step's outputs or outcome through \"steps.%s\"", id.Value, id.Value)
+	}
+	return nil
+}
+
+// collectRefs records the steps referenced through `steps.<id>` or
+// `steps['<id>']` in s.
+func (rule *RuleStepID) collectRefs(s *String) {
+	visitContextRefs(s, "steps", func(id string, _ int) {
+		if id == "" {
+			rule.allRefs = true
+		} else {
+			rule.refs[id] = true
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package compositeactionlint
+
+import (
+	"cmp"
+	"slices"
+)
+
+// RuleUnusedInput reports inputs of composite actions which no step or
+// output reads through the inputs context.
+type RuleUnusedInput struct {
+	RuleBase
+	// used are the lower case IDs of the inputs accessed so far
+	used map[string]bool
+	// allUsed is set when the inputs context is used as a whole, like in
+	// toJSON(inputs)
+	allUsed bool
+}
+
+func NewRuleUnusedInput() *RuleUnusedInput {
+	return &RuleUnusedInput{
+		RuleBase: RuleBase{
+			name: "unused-input",
+			desc: "Checks inputs of composite actions are used",
+		},
+	}
+}
+
+func (rule *RuleUnusedInput) VisitActionMetadataPre(n *ActionMetadata) error {
+	rule.used = map[string]bool{}
+	rule.allUsed = false
+	return nil
+}
+
+func (rule *RuleUnusedInput) VisitStep(n *Step) error {
+	for _, s := range compositeStepStrings(n) {
+		rule.collectRefs(s)
+	}
+	return nil
+}
+
+func (rule *RuleUnusedInput) VisitActionMetadataPost(n *ActionMetadata) error {
+	// Other actions read inputs from their environment
+	if n.Runs == nil || n.Runs.Using == nil || n.Runs.Using.Value != "composite" {
+		return nil
+	}
+
+	for _, o := range n.Outputs {
+		rule.collectRefs(o.Value)
+	}
+	if rule.allUsed {
+		return nil
+	}
+
+	unused := []*Input{}
+	for id, i := range n.Inputs {
+		if !rule.used[id] {
+			unused = append(unused, i)
+		}
+	}
+	slices.SortFunc(unused, func(a, b *Input) int {
+		return cmp.Or(cmp.Compare(a.Pos.Line, b.Pos.Line), cmp.Compare(a.Pos.Col, b.Pos.Col))
+	})
+	for _, i := range unused {
+		rule.Errorf(i.ID.Pos, "input %q is defined but never used by any step or output of this action. remove it or use it through \"inputs.%s\"", i.ID.Value, i.ID.Value)
+	}
+	return nil
+}
+
+// collectRefs records the inputs accessed through `inputs.<id>` or
+// `inputs['<id>']` in s.
+func (rule *RuleUnusedInput) collectRefs(s *String) {
+	visitContextRefs(s, "inputs", func(id string, _ int) {
+		if id == "" {
+			rule.allUsed = true
+		} else {
+			rule.used[id] = true
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package compositeactionlint
+
+import (
+	al "github.com/rhysd/actionlint"
+)
+
+// RuleUses checks the syntax of `uses:` in composite action steps.
+// https://docs.github.com/en/actions/reference/workflows-and-actions/metadata-syntax#runsstepsuses
+type RuleUses struct {
+	RuleBase
+}
+
+func NewRuleUses() *RuleUses {
+	return &RuleUses{
+		RuleBase: RuleBase{
+			name: "uses",
+			desc: "Checks the syntax of actions used by composite action steps",
+		},
+	}
+}
+
+func (rule *RuleUses) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*al.ExecAction)
+	if !ok || e.Uses == nil || e.Uses.Value == "" {
+		return nil
+	}
+
+	if e.Uses.ContainsExpression() {
+		// Cannot parse a spec made with interpolation
+		return nil
+	}
+
+	if _, err := parseActionRef(e.Uses.Value); err != nil {
+		rule.Errorf(e.Uses.Pos, "invalid action reference %q since %s. available formats are \"{owner}/{repo}@{ref}\", \"{owner}/{repo}/{path}@{ref}\", \"./{path}\" and \"docker://{image}:{tag}\"", e.Uses.Value, err)
+	}
+	return nil
+}
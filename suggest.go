@@ -0,0 +1,53 @@
+package compositeactionlint
+
+import "unicode/utf8"
+
+// maxSuggestionDistance is the maximum edit distance at which an unknown name
+// is still considered a typo of a known one. It grows with the length of the
+// name, so that short names are not matched with unrelated ones.
+func maxSuggestionDistance(s string) int {
+	return max((utf8.RuneCountInString(s)+2)/3, 1)
+}
+
+// closestMatch returns the candidate with the smallest edit distance to s, or
+// an empty string if no candidate is close enough to be a plausible typo.
+func closestMatch(s string, candidates []string) string {
+	best := ""
+	bestDist := maxSuggestionDistance(s) + 1
+	for _, c := range candidates {
+		if c == s {
+			continue
+		}
+		if d := editDistance(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b, treating a
+// transposition of two adjacent characters as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
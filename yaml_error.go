@@ -0,0 +1,203 @@
+package compositeactionlint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var yamlErrorRe = regexp.MustCompile(`^(?:yaml: )?(?:line (\d+): )?(.*)$`)
+var yamlUnknownAnchorRe = regexp.MustCompile(`^unknown anchor '(.*)' referenced$`)
+
+// yamlErrorHints explain what the YAML parser expected for its most common
+// complaints.
+var yamlErrorHints = map[string]string{
+	"did not find expected key":                              `expected a "key: value" entry at the indentation of the mapping`,
+	"did not find expected '-' indicator":                    `expected a "- " entry at the indentation of the sequence`,
+	"could not find expected ':'":                            `expected ":" after the mapping key`,
+	"mapping values are not allowed in this context":         `quote the value when it contains ": "`,
+	"found character that cannot start any token":            `quote the value when it starts with a special character such as "@" or "%"`,
+	"found a tab character that violates indentation":        `indent with spaces instead of tabs`,
+	"block sequence entries are not allowed in this context": `a "- " entry must start on its own line`,
+}
+
+// isYAMLParserError reports whether problem is raised by the parser rather
+// than the scanner of yaml.v3. The parser reports the 0-based line where the
+// enclosing node started, while the scanner reports the 1-based line of the
+// problem itself.
+func isYAMLParserError(problem string) bool {
+	return strings.HasPrefix(problem, "did not find expected")
+}
+
+func handleYAMLError(err error, src []byte) []*Error {
+	lines := sourceLines(src)
+
+	yamlErr := func(msg string) *Error {
+		ss := yamlErrorRe.FindStringSubmatch(msg)
+		problem := ss[2]
+		l := 1
+		if ss[1] != "" {
+			l, _ = strconv.Atoi(ss[1])
+			if isYAMLParserError(problem) {
+				l++
+			}
+		}
+
+		l, off := locateYAMLError(lines, l, problem)
+
+		msg = fmt.Sprintf("could not parse as YAML: %s", problem)
+		hint := yamlErrorHints[problem]
+		col := off
+		if l <= len(lines) {
+			line := lines[l-1]
+			if found := excerptAt(line, off); found != "" {
+				msg += fmt.Sprintf(" at %q", found)
+			}
+			if off >= 1 && off <= len(line) && line[off-1] == '\t' {
+				// Tabs are reported as characters which cannot start a token
+				// when they are not in indentation the scanner checks
+				hint = yamlErrorHints["found a tab character that violates indentation"]
+			}
+			// Columns are counted in characters like yaml.v3 does
+			col = utf8.RuneCountInString(line[:min(off-1, len(line))]) + 1
+		}
+		if hint != "" {
+			msg += ". " + hint
+		}
+		return newError(msg, "", l, col, "syntax-check")
+	}
+
+	if te, ok := err.(*yaml.TypeError); ok {
+		errs := make([]*Error, 0, len(te.Errors))
+		for _, msg := range te.Errors {
+			errs = append(errs, yamlErr(msg))
+		}
+		return errs
+	}
+
+	return []*Error{yamlErr(err.Error())}
+}
+
+// locateYAMLError finds the line and the 1-based byte offset in the line of
+// problem by re-scanning the source from line l, since yaml.v3 only reports a
+// line number and that line is sometimes where the enclosing node started
+// rather than the problem.
+func locateYAMLError(lines []string, l int, problem string) (int, int) {
+	if ss := yamlUnknownAnchorRe.FindStringSubmatch(problem); ss != nil {
+		for i, line := range lines {
+			if c := strings.Index(line, "*"+ss[1]); c >= 0 {
+				return i + 1, c + 1
+			}
+		}
+	}
+
+	if l > len(lines) {
+		l = len(lines)
+	}
+	line := lines[l-1]
+
+	switch problem {
+	case "did not find expected key", "did not find expected '-' indicator":
+		if i, c, ok := findBadEntry(lines, l, problem == "did not find expected key"); ok {
+			return i, c
+		}
+	case "mapping values are not allowed in this context":
+		content := line[parentIndent(line):]
+		if _, v, ok := cutMappingValue(content); ok {
+			if _, _, ok := cutMappingValue(v); ok {
+				// The second ":" in the line is the problem
+				return l, len(line) - len(v) + strings.Index(v+" ", ": ") + 1
+			}
+		}
+	case "found a tab character that violates indentation":
+		// Reported at the line before the tab when it indents a new entry
+		for i := l - 1; i < len(lines) && i <= l; i++ {
+			if c := strings.IndexByte(lines[i], '\t'); c >= 0 && c <= indentOf(lines[i]) {
+				return i + 1, c + 1
+			}
+		}
+	case "found character that cannot start any token":
+		if c := strings.IndexAny(line, "\t@`"); c >= 0 {
+			return l, c + 1
+		}
+	}
+
+	return l, indentOf(line) + 1
+}
+
+// findBadEntry scans the lines following the start of a block mapping or
+// sequence at line l for the first line which is neither nested in it nor a
+// valid entry of it.
+func findBadEntry(lines []string, l int, mapping bool) (int, int, bool) {
+	start := lines[l-1]
+	n := indentOf(start)
+	if mapping {
+		n = parentIndent(start)
+	}
+	prev := start[indentOf(start):]
+
+	for i := l; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		ind := indentOf(line)
+		isSeqEntry := trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+
+		if mapping {
+			if ind > n {
+				continue
+			}
+			// A sequence may be the value of the previous key without being
+			// further indented
+			if ind == n && isSeqEntry && (strings.HasSuffix(prev, ":") || strings.HasPrefix(prev, "-")) {
+				prev = trimmed
+				continue
+			}
+			if ind == n && !isSeqEntry {
+				if _, _, ok := cutMappingValue(trimmed); ok {
+					prev = trimmed
+					continue
+				}
+			}
+		} else {
+			if ind >= parentIndent(start) || (ind == n && isSeqEntry) {
+				continue
+			}
+		}
+		return i + 1, ind + 1, true
+	}
+	return 0, 0, false
+}
+
+// cutMappingValue splits a "key: value" entry into its key and value.
+func cutMappingValue(s string) (string, string, bool) {
+	if k, v, ok := strings.Cut(s, ": "); ok {
+		return k, v, true
+	}
+	if strings.HasSuffix(s, ":") {
+		return s[:len(s)-1], "", true
+	}
+	return s, "", false
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// excerptAt returns a short part of line starting at 1-based byte offset col.
+func excerptAt(line string, col int) string {
+	if col < 1 || col > len(line) {
+		return ""
+	}
+	s := strings.TrimSpace(line[col-1:])
+	if r := []rune(s); len(r) > 20 {
+		s = string(r[:20]) + "..."
+	}
+	return s
+}